@@ -20,7 +20,9 @@ go env -w CGO_ENABLED=1
 
 go test -race -count 1 ./...
 
-#go test -bench=BenchmarkController ./cmd/secrets
+go test -run '^$' -bench=BenchmarkTokenizer -benchmem ./token
+
+go test -run '^$' -fuzz '^FuzzTokenizer$' -fuzztime 30s ./token
 
 go env -w CGO_ENABLED=0
 
@@ -2,25 +2,170 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 
+	"github.com/udhos/yamlot/internal/cli"
 	"github.com/udhos/yamlot/token"
 )
 
+type config struct {
+	format   string
+	debug    bool
+	comments bool
+}
+
+// jsonToken is the JSON form of a token.
+type jsonToken struct {
+	File      string `json:"file,omitempty"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Offset    int    `json:"offset"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	EndOffset int    `json:"endOffset"`
+	Error     string `json:"error,omitempty"`
+}
+
 func main() {
-	const debug = false // set to true to enable debug output
-	tokenizer := token.NewTokenizer(os.Stdin, debug)
-	for {
-		t, err := tokenizer.NextToken()
-		if err == io.EOF && t.Type == token.TokenEOF {
-			break
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var cfg config
+
+	flags := flag.NewFlagSet("yamlot-tokenizer", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: yamlot-tokenizer [flags] [file|glob ...]\n")
+		fmt.Fprintf(stderr, "reads stdin when no file is given\n")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cfg.format, "format", "text", "output format: text, json or jsonl")
+	flags.BoolVar(&cfg.debug, "debug", false, "write tokenizer debug traces to stderr")
+	flags.BoolVar(&cfg.comments, "comments", false, "emit COMMENT tokens")
+	if err := flags.Parse(args); err != nil {
+		return cli.ExitUsage
+	}
+
+	switch cfg.format {
+	case "text", "json", "jsonl":
+	default:
+		fmt.Fprintf(stderr, "yamlot-tokenizer: unknown format: %q\n", cfg.format)
+		return cli.ExitUsage
+	}
+
+	files, err := cli.ExpandArgs(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "yamlot-tokenizer: %v\n", err)
+		return cli.ExitUsage
+	}
+
+	var all []jsonToken // for json format
+	status := cli.ExitOK
+
+	tokenizeOne := func(name string, input io.Reader) {
+		tokens, failed := tokenize(cfg, name, input, stdout, stderr)
+		all = append(all, tokens...)
+		if failed {
+			status = cli.ExitError
 		}
+	}
+
+	if len(files) == 0 {
+		tokenizeOne("", stdin)
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
 		if err != nil {
-			fmt.Printf("error: %v", err)
-			break
+			fmt.Fprintf(stderr, "yamlot-tokenizer: %v\n", err)
+			status = cli.ExitUsage
+			continue
+		}
+		if len(files) == 1 {
+			name = "" // do not label output of single file
 		}
-		fmt.Printf("line=%03d column=%02d: %s\n", t.Line, t.Column, t.String())
+		tokenizeOne(name, f)
+		f.Close()
 	}
+
+	if cfg.format == "json" {
+		if all == nil {
+			all = []jsonToken{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(all); err != nil {
+			fmt.Fprintf(stderr, "yamlot-tokenizer: %v\n", err)
+			return cli.ExitUsage
+		}
+	}
+
+	return status
+}
+
+// tokenize writes the tokens of one input. For json format the tokens
+// are returned to be written at the end instead.
+// It reports whether the input has errors, including ERROR tokens.
+func tokenize(cfg config, name string, input io.Reader, stdout, stderr io.Writer) ([]jsonToken, bool) {
+	options := token.Options{KeepComments: cfg.comments}
+	if cfg.debug {
+		options.Logger = slog.New(slog.NewTextHandler(stderr,
+			&slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	tokenizer := token.NewTokenizerWithOptions(input, options)
+
+	var tokens []jsonToken
+	var failed bool
+	enc := json.NewEncoder(stdout)
+
+	for t, err := range tokenizer.All() {
+		if t.Type == token.TokenError || err != nil {
+			failed = true
+		}
+
+		switch cfg.format {
+		case "text":
+			prefix := ""
+			if name != "" {
+				prefix = name + ": "
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "%serror: %v\n", prefix, err)
+				break
+			}
+			fmt.Fprintf(stdout, "%sline=%03d column=%02d: %s\n", prefix, t.Line, t.Column, t.String())
+		default:
+			jt := jsonToken{
+				File:      name,
+				Type:      t.Type.String(),
+				Value:     t.Value,
+				Line:      t.Line,
+				Column:    t.Column,
+				Offset:    t.Offset,
+				EndLine:   t.EndLine,
+				EndColumn: t.EndColumn,
+				EndOffset: t.EndOffset,
+			}
+			if err != nil {
+				jt.Error = err.Error()
+			}
+			if cfg.format == "json" {
+				tokens = append(tokens, jt)
+				break
+			}
+			if errEnc := enc.Encode(jt); errEnc != nil {
+				fmt.Fprintf(stderr, "yamlot-tokenizer: %v\n", errEnc)
+				return tokens, true
+			}
+		}
+	}
+
+	return tokens, failed
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type runTest struct {
+	name           string
+	args           []string
+	stdin          string
+	expectedStatus int
+	expectedStdout string // substring, empty to skip check
+	expectedStderr string // substring, empty to skip check
+	absentStdout   string // substring that must not appear, empty to skip check
+}
+
+var runTestTable = []runTest{
+	{"stdin-text", nil, "- a\n", 0, "line=001 column=01: DASH\nline=001 column=03: PLAIN-SCALAR(a)\n", "", ""},
+	{"error-token", nil, "\tx\n", 1, "ERROR(TabIndentation: tab character used for indentation at column 1)", "", ""},
+	{"invalid-utf8", nil, "- \xff\n", 1, "", "error: line 1, column 3: invalid encoding", ""},
+	{"comments-off", nil, "- a # c\n", 0, "PLAIN-SCALAR(a)", "", "COMMENT"},
+	{"comments-on", []string{"-comments"}, "- a # c\n", 0, "COMMENT(# c)", "", ""},
+	{"debug", []string{"-debug"}, "- a\n", 0, "", "tokenBufferPush", ""},
+	{"unknown-format", []string{"-format=xml"}, "", 2, "", `unknown format: "xml"`, ""},
+	{"bad-flag", []string{"-nope"}, "", 2, "", "flag provided but not defined", ""},
+	{"missing-file", []string{"missing.yaml"}, "", 2, "", "missing.yaml", ""},
+	{"bad-pattern", []string{"["}, "", 2, "", "bad pattern", ""},
+}
+
+// go test -count 1 -run '^TestRun$' ./...
+func TestRun(t *testing.T) {
+	for i, data := range runTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(runTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(data.args, strings.NewReader(data.stdin), &stdout, &stderr)
+			if status != data.expectedStatus {
+				t.Errorf("expecting status %d, got: %d (stderr: %q)", data.expectedStatus, status, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), data.expectedStdout) {
+				t.Errorf("expecting stdout with %q, got: %q", data.expectedStdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), data.expectedStderr) {
+				t.Errorf("expecting stderr with %q, got: %q", data.expectedStderr, stderr.String())
+			}
+			if data.absentStdout != "" && strings.Contains(stdout.String(), data.absentStdout) {
+				t.Errorf("unexpected %q in stdout: %q", data.absentStdout, stdout.String())
+			}
+		})
+	}
+}
+
+// writeFiles creates files in a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// go test -count 1 -run '^TestRunGlob$' ./...
+func TestRunGlob(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.yaml": "- a\n", "b.yaml": "- b\n", "c.txt": "- c\n"})
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{filepath.Join(dir, "*.yaml")}, strings.NewReader(""), &stdout, &stderr)
+	if status != 0 {
+		t.Errorf("expecting status 0, got: %d (stderr: %q)", status, stderr.String())
+	}
+	for _, expected := range []string{
+		filepath.Join(dir, "a.yaml") + ": line=001 column=03: PLAIN-SCALAR(a)",
+		filepath.Join(dir, "b.yaml") + ": line=001 column=03: PLAIN-SCALAR(b)",
+	} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expecting %q in stdout, got: %q", expected, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "c.txt") {
+		t.Errorf("unexpected c.txt in stdout: %q", stdout.String())
+	}
+
+	// single file output is not labelled, errors in any file fail
+	stdout.Reset()
+	dir = writeFiles(t, map[string]string{"bad.yaml": "\tx\n"})
+	status = run([]string{filepath.Join(dir, "bad.yaml")}, strings.NewReader(""), &stdout, &stderr)
+	if status != 1 {
+		t.Errorf("expecting status 1, got: %d", status)
+	}
+	if !strings.HasPrefix(stdout.String(), "line=001") {
+		t.Errorf("expecting unlabelled output, got: %q", stdout.String())
+	}
+}
+
+// go test -count 1 -run '^TestRunJSON$' ./...
+func TestRunJSON(t *testing.T) {
+	const input = "- a\n- \xff\n"
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"-format=jsonl"}, strings.NewReader(input), &stdout, &stderr)
+	if status != 1 {
+		t.Errorf("jsonl: expecting status 1, got: %d", status)
+	}
+	var lines []jsonToken
+	for line := range strings.Lines(stdout.String()) {
+		var tk jsonToken
+		if err := json.Unmarshal([]byte(line), &tk); err != nil {
+			t.Fatalf("jsonl: line %q: %v", line, err)
+		}
+		lines = append(lines, tk)
+	}
+	if len(lines) != 5 {
+		t.Fatalf("jsonl: expecting 5 tokens, got: %v", lines)
+	}
+	expected := jsonToken{Type: "PLAIN-SCALAR", Value: "a", Line: 1, Column: 3, Offset: 2, EndLine: 1, EndColumn: 4, EndOffset: 3}
+	if lines[1] != expected {
+		t.Errorf("jsonl: expecting %+v, got: %+v", expected, lines[1])
+	}
+	if last := lines[len(lines)-1]; last.Type != "ERROR" || !strings.Contains(last.Error, "invalid encoding") {
+		t.Errorf("jsonl: expecting error token last, got: %+v", last)
+	}
+
+	stdout.Reset()
+	status = run([]string{"-format=json"}, strings.NewReader("- a\n"), &stdout, &stderr)
+	if status != 0 {
+		t.Errorf("json: expecting status 0, got: %d", status)
+	}
+	var array []jsonToken
+	if err := json.Unmarshal(stdout.Bytes(), &array); err != nil {
+		t.Fatalf("json: %v: %q", err, stdout.String())
+	}
+	if len(array) != 3 || array[0].Type != "DASH" {
+		t.Errorf("json: expecting DASH PLAIN-SCALAR NEWLINE, got: %+v", array)
+	}
+
+	stdout.Reset()
+	run([]string{"-format=json"}, strings.NewReader(""), &stdout, &stderr)
+	if strings.TrimSpace(stdout.String()) != "[]" {
+		t.Errorf("json: expecting empty array for empty input, got: %q", stdout.String())
+	}
+}
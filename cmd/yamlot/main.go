@@ -0,0 +1,141 @@
+// Package main implements the yamlot tool.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+
+	"github.com/udhos/yamlot/highlight"
+	"github.com/udhos/yamlot/internal/cli"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func usage(stderr io.Writer) {
+	fmt.Fprintf(stderr, "usage: yamlot <command> [flags] [file|glob ...]\n")
+	fmt.Fprintf(stderr, "commands:\n")
+	fmt.Fprintf(stderr, "  cat    print files, optionally syntax highlighted\n")
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return cli.ExitUsage
+	}
+	switch args[0] {
+	case "cat":
+		return runCat(args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		usage(stderr)
+		return cli.ExitOK
+	}
+	fmt.Fprintf(stderr, "yamlot: unknown command: %q\n", args[0])
+	usage(stderr)
+	return cli.ExitUsage
+}
+
+func runCat(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("yamlot cat", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: yamlot cat [flags] [file|glob ...]\n")
+		fmt.Fprintf(stderr, "reads stdin when no file is given\n")
+		flags.PrintDefaults()
+	}
+	color := flags.Bool("color", false, "highlight with ANSI terminal colors")
+	asHTML := flags.Bool("html", false, "write a self-contained highlighted HTML document, one section per file")
+	if err := flags.Parse(args); err != nil {
+		return cli.ExitUsage
+	}
+	if *color && *asHTML {
+		fmt.Fprintf(stderr, "yamlot cat: -color and -html are exclusive\n")
+		return cli.ExitUsage
+	}
+
+	files, err := cli.ExpandArgs(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "yamlot cat: %v\n", err)
+		return cli.ExitUsage
+	}
+
+	status := cli.ExitOK
+
+	write := func(b []byte) bool {
+		if _, err := stdout.Write(b); err != nil {
+			fmt.Fprintf(stderr, "yamlot cat: %v\n", err)
+			status = cli.ExitUsage
+			return false
+		}
+		return true
+	}
+
+	// all files go into one HTML document, one section per file
+	if *asHTML {
+		var buf bytes.Buffer
+		title := "stdin"
+		if len(files) == 1 {
+			title = files[0]
+		} else if len(files) > 1 {
+			title = "yamlot"
+		}
+		_ = highlight.HTMLHeader(&buf, title) // bytes.Buffer does not fail
+		if !write(buf.Bytes()) {
+			return status
+		}
+	}
+
+	catOne := func(name string, input []byte) {
+		var buf bytes.Buffer
+		var err error
+		switch {
+		case *color:
+			err = highlight.ANSI(&buf, input)
+		case *asHTML:
+			if len(files) > 1 {
+				fmt.Fprintf(&buf, "<h2>%s</h2>\n", html.EscapeString(name))
+			}
+			err = highlight.HTMLFragment(&buf, input)
+		default:
+			buf.Write(input)
+		}
+		if !write(buf.Bytes()) {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "yamlot cat: %s: %v\n", name, err)
+			status = cli.ExitError
+		}
+	}
+
+	if len(files) == 0 {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "yamlot cat: %v\n", err)
+			return cli.ExitUsage
+		}
+		catOne("stdin", input)
+	}
+	for _, name := range files {
+		input, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "yamlot cat: %v\n", err)
+			status = cli.ExitUsage
+			continue
+		}
+		catOne(name, input)
+	}
+
+	if *asHTML {
+		var buf bytes.Buffer
+		_ = highlight.HTMLFooter(&buf) // bytes.Buffer does not fail
+		write(buf.Bytes())
+	}
+
+	return status
+}
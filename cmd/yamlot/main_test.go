@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+type runTest struct {
+	name           string
+	args           []string
+	stdin          string
+	expectedStatus int
+	expectedStdout string // exact stdout, empty to skip check
+	expectedStderr string // substring, empty to skip check
+}
+
+var runTestTable = []runTest{
+	{"no-command", nil, "", 2, "", "usage: yamlot"},
+	{"unknown-command", []string{"dog"}, "", 2, "", `unknown command: "dog"`},
+	{"help", []string{"help"}, "", 0, "", "usage: yamlot"},
+	{"cat-plain", []string{"cat"}, "- 1 # c\n", 0, "- 1 # c\n", ""},
+	{"cat-color", []string{"cat", "-color"}, "- 1 # c\n", 0,
+		"\x1b[1;36m-\x1b[0m \x1b[34m1\x1b[0m \x1b[90m# c\x1b[0m\n", ""},
+	{"cat-color-error", []string{"cat", "-color"}, "- \xff\n", 1, "", "invalid encoding"},
+	{"cat-color-html", []string{"cat", "-color", "-html"}, "", 2, "", "exclusive"},
+	{"cat-bad-flag", []string{"cat", "-nope"}, "", 2, "", "flag provided but not defined"},
+	{"cat-missing-file", []string{"cat", "missing.yaml"}, "", 2, "", "missing.yaml"},
+}
+
+// go test -count 1 -run '^TestRun$' ./...
+func TestRun(t *testing.T) {
+	for i, data := range runTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(runTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(data.args, strings.NewReader(data.stdin), &stdout, &stderr)
+			if status != data.expectedStatus {
+				t.Errorf("expecting status %d, got: %d (stderr: %q)", data.expectedStatus, status, stderr.String())
+			}
+			if data.expectedStdout != "" && stdout.String() != data.expectedStdout {
+				t.Errorf("expecting stdout %q, got: %q", data.expectedStdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), data.expectedStderr) {
+				t.Errorf("expecting stderr with %q, got: %q", data.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestCatHTML$' ./...
+func TestCatHTML(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.yaml": "- a\n", "b.yaml": "- <b>\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"cat", "-html", filepath.Join(dir, "*.yaml")}, strings.NewReader(""), &stdout, &stderr)
+	if status != 0 {
+		t.Errorf("expecting status 0, got: %d (stderr: %q)", status, stderr.String())
+	}
+	out := stdout.String()
+	if n := strings.Count(out, "<!DOCTYPE html>"); n != 1 {
+		t.Errorf("expecting one document, got %d: %s", n, out)
+	}
+	if !strings.HasSuffix(out, "</body>\n</html>\n") {
+		t.Errorf("expecting document end, got: %s", out)
+	}
+	for _, expected := range []string{
+		"<h2>" + filepath.Join(dir, "a.yaml") + "</h2>",
+		"<h2>" + filepath.Join(dir, "b.yaml") + "</h2>",
+		`<span class="yamlot-string">&lt;b&gt;</span>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expecting %q in output: %s", expected, out)
+		}
+	}
+	if strings.Index(out, "a.yaml</h2>") > strings.Index(out, "b.yaml</h2>") {
+		t.Errorf("expecting files in order: %s", out)
+	}
+
+	// single input has no heading
+	stdout.Reset()
+	run([]string{"cat", "-html"}, strings.NewReader("- a\n"), &stdout, &stderr)
+	if strings.Contains(stdout.String(), "<h2>") {
+		t.Errorf("unexpected heading for single input: %s", stdout.String())
+	}
+}
+
+// go test -count 1 -run '^TestCatUTF16$' ./...
+func TestCatUTF16(t *testing.T) {
+	input := binary.LittleEndian.AppendUint16(nil, 0xFEFF)
+	for _, u := range utf16.Encode([]rune("- ação\n")) {
+		input = binary.LittleEndian.AppendUint16(input, u)
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"cat", "-color"}, bytes.NewReader(input), &stdout, &stderr)
+	if status != 0 {
+		t.Errorf("expecting status 0, got: %d (stderr: %q)", status, stderr.String())
+	}
+	const expected = "\x1b[1;36m-\x1b[0m \x1b[32mação\x1b[0m\n"
+	if stdout.String() != expected {
+		t.Errorf("expecting %q, got: %q", expected, stdout.String())
+	}
+
+	stdout.Reset()
+	status = run([]string{"cat", "-color"}, bytes.NewReader(input[:len(input)-1]), &stdout, &stderr)
+	if status != 1 {
+		t.Errorf("expecting status 1 for truncated UTF-16, got: %d", status)
+	}
+}
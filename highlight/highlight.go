@@ -0,0 +1,220 @@
+// Package highlight renders YAML with syntax highlighting.
+//
+// Highlighting is driven by the token stream from token.Tokenizer, so it
+// reflects exactly how yamlot lexes the input. Text outside of tokens,
+// such as indentation and separation blanks, is copied unchanged.
+//
+// UTF-16 and UTF-32 input is transcoded, so the output is always UTF-8.
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/udhos/yamlot/token"
+)
+
+// Class identifies how a token is highlighted.
+type Class int
+
+// Highlight classes.
+const (
+	ClassNone      Class = iota // not highlighted
+	ClassComment                // # comment
+	ClassDocMarker              // --- and ...
+	ClassIndicator              // - sequence entry
+	ClassNull                   // plain scalar resolved to null
+	ClassBool                   // plain scalar resolved to bool
+	ClassInt                    // plain scalar resolved to int
+	ClassFloat                  // plain scalar resolved to float
+	ClassString                 // plain scalar resolved to string
+	ClassError                  // tokenize error
+)
+
+var className = []string{
+	"none",
+	"comment",
+	"doc-marker",
+	"indicator",
+	"null",
+	"bool",
+	"int",
+	"float",
+	"string",
+	"error",
+}
+
+// String returns the class name, also used as CSS class suffix.
+func (c Class) String() string {
+	if c < 0 || int(c) >= len(className) {
+		return fmt.Sprintf("Class(%d)", int(c))
+	}
+	return className[c]
+}
+
+// Plain scalar resolution from the YAML 1.2 core schema (section 10.3.2).
+var (
+	coreNull  = regexp.MustCompile(`^(?:~|null|Null|NULL|)$`)
+	coreBool  = regexp.MustCompile(`^(?:true|True|TRUE|false|False|FALSE)$`)
+	coreInt   = regexp.MustCompile(`^(?:[-+]?[0-9]+|0o[0-7]+|0x[0-9a-fA-F]+)$`)
+	coreFloat = regexp.MustCompile(`^(?:[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?|[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN))$`)
+)
+
+// ClassOf returns the highlight class of a token.
+// Plain scalars are classified by the type they resolve to under the
+// YAML 1.2 core schema.
+func ClassOf(tk token.Token) Class {
+	switch tk.Type {
+	case token.TokenComment:
+		return ClassComment
+	case token.TokenDocStart, token.TokenDocEnd:
+		return ClassDocMarker
+	case token.TokenDash:
+		return ClassIndicator
+	case token.TokenError:
+		return ClassError
+	case token.TokenPlainScalar:
+		switch {
+		case coreNull.MatchString(tk.Value):
+			return ClassNull
+		case coreBool.MatchString(tk.Value):
+			return ClassBool
+		case coreInt.MatchString(tk.Value):
+			return ClassInt
+		case coreFloat.MatchString(tk.Value):
+			return ClassFloat
+		}
+		return ClassString
+	}
+	return ClassNone
+}
+
+// ansiColor holds the SGR parameters for each class.
+var ansiColor = []string{
+	ClassNone:      "",
+	ClassComment:   "90",
+	ClassDocMarker: "1;35",
+	ClassIndicator: "1;36",
+	ClassNull:      "35",
+	ClassBool:      "33",
+	ClassInt:       "34",
+	ClassFloat:     "34",
+	ClassString:    "32",
+	ClassError:     "4;31",
+}
+
+// CSS holds the style sheet for the classes written by HTML and
+// HTMLFragment.
+const CSS = `.yamlot { background: #fdfdfd; color: #222; }
+.yamlot-comment { color: #888; font-style: italic; }
+.yamlot-doc-marker { color: #a626a4; font-weight: bold; }
+.yamlot-indicator { color: #0184bc; font-weight: bold; }
+.yamlot-null { color: #a626a4; }
+.yamlot-bool { color: #c18401; }
+.yamlot-int, .yamlot-float { color: #4078f2; }
+.yamlot-string { color: #50a14f; }
+.yamlot-error { color: #e45649; text-decoration: underline wavy; }
+`
+
+// style tells how to wrap highlighted text.
+type style struct {
+	open   func(c Class) string
+	close  func(c Class) string
+	escape func(s string) string
+}
+
+var ansiStyle = style{
+	open:   func(c Class) string { return "\x1b[" + ansiColor[c] + "m" },
+	close:  func(Class) string { return "\x1b[0m" },
+	escape: func(s string) string { return s },
+}
+
+var htmlStyle = style{
+	open:   func(c Class) string { return `<span class="yamlot-` + c.String() + `">` },
+	close:  func(Class) string { return "</span>" },
+	escape: html.EscapeString,
+}
+
+// ANSI writes input highlighted with ANSI terminal colors.
+func ANSI(w io.Writer, input []byte) error {
+	return render(w, input, ansiStyle)
+}
+
+// HTMLFragment writes input highlighted as an HTML pre element, with CSS
+// classes named yamlot-<class>. See CSS for a matching style sheet.
+func HTMLFragment(w io.Writer, input []byte) error {
+	if _, err := io.WriteString(w, `<pre class="yamlot">`); err != nil {
+		return err
+	}
+	errRender := render(w, input, htmlStyle)
+	if _, err := io.WriteString(w, "</pre>\n"); err != nil {
+		return err
+	}
+	return errRender
+}
+
+// HTML writes input highlighted as a self-contained HTML document.
+func HTML(w io.Writer, input []byte, title string) error {
+	if err := HTMLHeader(w, title); err != nil {
+		return err
+	}
+	errRender := HTMLFragment(w, input)
+	if err := HTMLFooter(w); err != nil {
+		return err
+	}
+	return errRender
+}
+
+// HTMLHeader writes the start of a self-contained HTML document, up to
+// the opening body tag, with CSS embedded. Use it with HTMLFragment and
+// HTMLFooter to render several inputs into one document.
+func HTMLHeader(w io.Writer, title string) error {
+	_, err := fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n",
+		html.EscapeString(title), CSS)
+	return err
+}
+
+// HTMLFooter writes the end of the document started by HTMLHeader.
+func HTMLFooter(w io.Writer) error {
+	_, err := io.WriteString(w, "</body>\n</html>\n")
+	return err
+}
+
+// render copies input to w as UTF-8, wrapping the text of each
+// highlighted token. On a tokenize or transcoding error the rest of the
+// decoded input is copied unhighlighted and the error is returned.
+func render(w io.Writer, input []byte, s style) error {
+	var sb strings.Builder
+	var written int // input bytes already copied
+	var errTokenize error
+
+	input, errTokenize = token.ToUTF8(input) // offsets refer to UTF-8 text
+
+	tokenizer := token.NewBytesTokenizerWithOptions(input, token.Options{KeepComments: true})
+	for tk, err := range tokenizer.All() {
+		if err != nil {
+			errTokenize = err // error token returned with err ends the stream
+		}
+		class := ClassOf(tk)
+		if class == ClassNone || tk.Offset == tk.EndOffset {
+			continue
+		}
+		if tk.Offset < written || tk.EndOffset > len(input) {
+			return fmt.Errorf("highlight: token %s span %d-%d does not fit input", tk.String(), tk.Offset, tk.EndOffset)
+		}
+		sb.WriteString(s.escape(string(input[written:tk.Offset])))
+		sb.WriteString(s.open(class))
+		sb.WriteString(s.escape(string(input[tk.Offset:tk.EndOffset])))
+		sb.WriteString(s.close(class))
+		written = tk.EndOffset
+	}
+	sb.WriteString(s.escape(string(input[written:])))
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+	return errTokenize
+}
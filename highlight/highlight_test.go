@@ -0,0 +1,136 @@
+package highlight
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"regexp"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/udhos/yamlot/token"
+)
+
+var classOfTestTable = []struct {
+	value string
+	class Class
+}{
+	{"", ClassNull},
+	{"~", ClassNull},
+	{"null", ClassNull},
+	{"NULL", ClassNull},
+	{"nULL", ClassString},
+	{"true", ClassBool},
+	{"False", ClassBool},
+	{"yes", ClassString},
+	{"0", ClassInt},
+	{"-12", ClassInt},
+	{"0o17", ClassInt},
+	{"0x1F", ClassInt},
+	{"0x1G", ClassString},
+	{"1.5", ClassFloat},
+	{"1.", ClassFloat},
+	{".5", ClassFloat},
+	{"1e3", ClassFloat},
+	{"-.inf", ClassFloat},
+	{".NaN", ClassFloat},
+	{"1.2.3", ClassString},
+	{"hello world", ClassString},
+}
+
+// go test -count 1 -run '^TestClassOf$' ./...
+func TestClassOf(t *testing.T) {
+	for i, data := range classOfTestTable {
+		name := fmt.Sprintf("%02d of %02d: %q", i+1, len(classOfTestTable), data.value)
+
+		t.Run(name, func(t *testing.T) {
+			class := ClassOf(token.Token{Type: token.TokenPlainScalar, Value: data.value})
+			if class != data.class {
+				t.Errorf("expecting class %s, got: %s", data.class, class)
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestANSI$' ./...
+func TestANSI(t *testing.T) {
+	const input = "# c\n- 1\n- a #x\n---\n"
+	const expected = "\x1b[90m# c\x1b[0m\n" +
+		"\x1b[1;36m-\x1b[0m \x1b[34m1\x1b[0m\n" +
+		"\x1b[1;36m-\x1b[0m \x1b[32ma\x1b[0m \x1b[90m#x\x1b[0m\n" +
+		"\x1b[1;35m---\x1b[0m\n"
+
+	var buf bytes.Buffer
+	if err := ANSI(&buf, []byte(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expecting %q, got: %q", expected, buf.String())
+	}
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// go test -count 1 -run '^TestHTMLFragment$' ./...
+func TestHTMLFragment(t *testing.T) {
+	const input = "- <a & b>\n-\t# tab\n  - folded\n\n  text\n...\n"
+
+	var buf bytes.Buffer
+	if err := HTMLFragment(&buf, []byte(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !bytes.Contains(buf.Bytes(), []byte(`<span class="yamlot-string">&lt;a &amp; b&gt;</span>`)) {
+		t.Errorf("expecting escaped string span, got: %s", out)
+	}
+	if text := html.UnescapeString(htmlTag.ReplaceAllString(out, "")); text != input+"\n" {
+		t.Errorf("expecting text %q, got: %q", input+"\n", text)
+	}
+}
+
+// go test -count 1 -run '^TestHighlightError$' ./...
+func TestHighlightError(t *testing.T) {
+	const input = "- a\n- \xff\n- b\n"
+
+	var buf bytes.Buffer
+	if err := ANSI(&buf, []byte(input)); err == nil {
+		t.Error("expecting error for invalid UTF-8")
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\xff\n- b\n")) {
+		t.Errorf("expecting rest of input copied, got: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := ANSI(&buf, []byte("\tx\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x1b[4;31m\t\x1b[0m")) {
+		t.Errorf("expecting highlighted tab indentation, got: %q", buf.String())
+	}
+}
+
+// go test -count 1 -run '^TestHighlightUTF16$' ./...
+func TestHighlightUTF16(t *testing.T) {
+	const input = "- ação # c\n- 日本\n"
+
+	utf16LE := binary.LittleEndian.AppendUint16(nil, 0xFEFF)
+	for _, u := range utf16.Encode([]rune(input)) {
+		utf16LE = binary.LittleEndian.AppendUint16(utf16LE, u)
+	}
+
+	var expected, got bytes.Buffer
+	if err := ANSI(&expected, []byte(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ANSI(&got, utf16LE); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.String() != expected.String() {
+		t.Errorf("expecting %q, got: %q", expected.String(), got.String())
+	}
+
+	if err := ANSI(&got, utf16LE[:len(utf16LE)-1]); err == nil {
+		t.Error("expecting error for truncated UTF-16")
+	}
+}
@@ -0,0 +1,32 @@
+// Package cli holds helpers shared by the yamlot commands.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Exit status.
+const (
+	ExitOK    = 0
+	ExitError = 1 // input has tokenize errors
+	ExitUsage = 2 // bad arguments or unreadable input
+)
+
+// ExpandArgs expands glob patterns. An argument that is not a pattern
+// is kept as is, so that a missing file is reported when opened.
+func ExpandArgs(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			files = append(files, arg)
+			continue
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
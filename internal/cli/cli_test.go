@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// go test -count 1 -run '^TestExpandArgs$' ./...
+func TestExpandArgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yaml", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(dir, "missing.yaml")
+
+	files, err := ExpandArgs([]string{filepath.Join(dir, "*.yaml"), missing})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml"), missing}
+	if !slices.Equal(files, expected) {
+		t.Errorf("expecting %v, got: %v", expected, files)
+	}
+
+	if _, err := ExpandArgs([]string{"["}); err == nil {
+		t.Error("expecting error for bad pattern")
+	}
+}
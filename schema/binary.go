@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TagBinary is the tag of base64 encoded binary scalars.
+const TagBinary = "tag:yaml.org,2002:binary"
+
+// ErrInvalidBinary reports a scalar that is not valid base64.
+var ErrInvalidBinary = errors.New("invalid binary")
+
+// ParseBinary decodes a binary scalar from base64, as described in
+// https://yaml.org/type/binary.html. White space, such as the line
+// breaks of a block scalar, is ignored.
+func ParseBinary(s string) ([]byte, error) {
+	clean := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, s)
+	b, err := base64.StdEncoding.DecodeString(clean)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBinary, err)
+	}
+	return b, nil
+}
+
+// FormatBinary encodes b as base64. Breaking long values into lines is
+// left to the emitter.
+func FormatBinary(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
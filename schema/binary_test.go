@@ -0,0 +1,29 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// go test -count 1 -run '^TestParseBinary$' ./...
+func TestParseBinary(t *testing.T) {
+	const input = "R0lGODlh\n  DAAMAIQA\n"
+
+	b, err := ParseBinary(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(b, []byte("GIF89a")) {
+		t.Errorf("expecting GIF header, got: %q", b[:6])
+	}
+	if got, _ := ParseBinary(FormatBinary(b)); !bytes.Equal(got, b) {
+		t.Errorf("round trip: expecting %q, got: %q", b, got)
+	}
+
+	for _, invalid := range []string{"R0lGOD!h", "R0lGODl"} {
+		if _, err := ParseBinary(invalid); !errors.Is(err, ErrInvalidBinary) {
+			t.Errorf("%q: expecting ErrInvalidBinary, got: %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/udhos/yamlot"
+)
+
+// Tags of the collection types.
+const (
+	TagSet   = "tag:yaml.org,2002:set"
+	TagOmap  = "tag:yaml.org,2002:omap"
+	TagPairs = "tag:yaml.org,2002:pairs"
+)
+
+// Errors reporting collections that do not have the form of their type.
+var (
+	ErrInvalidSet   = errors.New("invalid set")
+	ErrInvalidOmap  = errors.New("invalid omap")
+	ErrInvalidPairs = errors.New("invalid pairs")
+)
+
+// ParseSet decodes a set, as described in https://yaml.org/type/set.html:
+// a mapping whose values are all null. It returns the members in order.
+func ParseSet(v yamlot.Value) ([]yamlot.Value, error) {
+	if v.Kind() != yamlot.KindMapping {
+		return nil, fmt.Errorf("%w: expecting mapping, got %s", ErrInvalidSet, v.Kind())
+	}
+	members := make([]yamlot.Value, 0, v.Len())
+	for key, value := range v.Mapping().All() {
+		if !value.IsNull() {
+			return nil, fmt.Errorf("%w: member %q has %s value", ErrInvalidSet, key, value.Kind())
+		}
+		members = append(members, key)
+	}
+	return members, nil
+}
+
+// FormatSet encodes members as a mapping with null values.
+// A repeated member is kept once.
+func FormatSet(members []yamlot.Value) yamlot.Value {
+	v := yamlot.NewMapping()
+	for _, key := range members {
+		v.Mapping().Set(key, yamlot.NewNull())
+	}
+	return v
+}
+
+// ParseOmap decodes an ordered map, as described in
+// https://yaml.org/type/omap.html: a sequence of single-pair mappings
+// with unique keys. It returns a mapping keeping the sequence order.
+func ParseOmap(v yamlot.Value) (yamlot.Value, error) {
+	pairs, err := parsePairs(v, ErrInvalidOmap)
+	if err != nil {
+		return yamlot.Value{}, err
+	}
+	m := yamlot.NewMapping()
+	for i, p := range pairs {
+		if _, found := m.Mapping().Get(p.Key); found {
+			return yamlot.Value{}, fmt.Errorf("%w: item %d: duplicate key %q", ErrInvalidOmap, i, p.Key)
+		}
+		m.Mapping().Set(p.Key, p.Value)
+	}
+	return m, nil
+}
+
+// FormatOmap encodes the mapping m as a sequence of single-pair mappings.
+func FormatOmap(m *yamlot.Mapping) yamlot.Value {
+	return FormatPairs(m.Pairs())
+}
+
+// ParsePairs decodes pairs, as described in
+// https://yaml.org/type/pairs.html: a sequence of single-pair mappings,
+// where keys may repeat.
+func ParsePairs(v yamlot.Value) ([]yamlot.Pair, error) {
+	return parsePairs(v, ErrInvalidPairs)
+}
+
+// FormatPairs encodes pairs as a sequence of single-pair mappings.
+func FormatPairs(pairs []yamlot.Pair) yamlot.Value {
+	items := make([]yamlot.Value, 0, len(pairs))
+	for _, p := range pairs {
+		items = append(items, yamlot.NewMapping(p))
+	}
+	return yamlot.NewSequence(items...)
+}
+
+// parsePairs decodes a sequence of single-pair mappings, reporting
+// errors wrapped in errInvalid.
+func parsePairs(v yamlot.Value, errInvalid error) ([]yamlot.Pair, error) {
+	if v.Kind() != yamlot.KindSequence {
+		return nil, fmt.Errorf("%w: expecting sequence, got %s", errInvalid, v.Kind())
+	}
+	pairs := make([]yamlot.Pair, 0, v.Len())
+	for i, item := range v.Items() {
+		if item.Kind() != yamlot.KindMapping || item.Len() != 1 {
+			return nil, fmt.Errorf("%w: item %d: expecting single-pair mapping", errInvalid, i)
+		}
+		pairs = append(pairs, item.Mapping().Pairs()[0])
+	}
+	return pairs, nil
+}
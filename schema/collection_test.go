@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/udhos/yamlot"
+)
+
+func str(s string) yamlot.Value {
+	return yamlot.NewString(s)
+}
+
+func single(key, value yamlot.Value) yamlot.Value {
+	return yamlot.NewMapping(yamlot.Pair{Key: key, Value: value})
+}
+
+// go test -count 1 -run '^TestParseSet$' ./...
+func TestParseSet(t *testing.T) {
+	// ? Mark McGwire
+	// ? Sammy Sosa
+	// ? Ken Griffey
+	input := FormatSet([]yamlot.Value{str("Mark McGwire"), str("Sammy Sosa"), str("Ken Griffey"), str("Sammy Sosa")})
+
+	members, err := ParseSet(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := yamlot.NewSequence(members...); !got.Equal(yamlot.NewSequence(str("Mark McGwire"), str("Sammy Sosa"), str("Ken Griffey"))) {
+		t.Errorf("expecting members in order without repetition, got: %v", members)
+	}
+
+	invalid := []yamlot.Value{
+		yamlot.NewSequence(str("a")),
+		yamlot.NewMapping(yamlot.Pair{Key: str("a"), Value: str("b")}),
+	}
+	for _, v := range invalid {
+		if _, err := ParseSet(v); !errors.Is(err, ErrInvalidSet) {
+			t.Errorf("%s: expecting ErrInvalidSet, got: %v", v.Kind(), err)
+		}
+	}
+}
+
+// go test -count 1 -run '^TestParseOmap$' ./...
+func TestParseOmap(t *testing.T) {
+	// - Mark McGwire: 65
+	// - Sammy Sosa: 63
+	// - Ken Griffy: 58
+	input := yamlot.NewSequence(
+		single(str("Mark McGwire"), yamlot.NewInt(65)),
+		single(str("Sammy Sosa"), yamlot.NewInt(63)),
+		single(str("Ken Griffy"), yamlot.NewInt(58)),
+	)
+
+	m, err := ParseOmap(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var keys []string
+	for key := range m.Mapping().All() {
+		keys = append(keys, key.String())
+	}
+	if len(keys) != 3 || keys[0] != "Mark McGwire" || keys[2] != "Ken Griffy" {
+		t.Errorf("expecting keys in sequence order, got: %q", keys)
+	}
+	if got := m.Get("Sammy Sosa").Int(); got != 63 {
+		t.Errorf("expecting 63, got: %d", got)
+	}
+	if got := FormatOmap(m.Mapping()); !got.Equal(input) {
+		t.Errorf("round trip: expecting %v, got: %v", input, got)
+	}
+
+	invalid := []yamlot.Value{
+		single(str("a"), str("b")),
+		yamlot.NewSequence(str("a")),
+		yamlot.NewSequence(yamlot.NewMapping(
+			yamlot.Pair{Key: str("a"), Value: str("b")},
+			yamlot.Pair{Key: str("c"), Value: str("d")},
+		)),
+		yamlot.NewSequence(single(str("a"), str("b")), single(str("a"), str("c"))),
+	}
+	for i, v := range invalid {
+		if _, err := ParseOmap(v); !errors.Is(err, ErrInvalidOmap) {
+			t.Errorf("case %d: expecting ErrInvalidOmap, got: %v", i, err)
+		}
+	}
+}
+
+// go test -count 1 -run '^TestParsePairs$' ./...
+func TestParsePairs(t *testing.T) {
+	// - meeting: with team.
+	// - meeting: with boss.
+	// - break: lunch.
+	input := yamlot.NewSequence(
+		single(str("meeting"), str("with team.")),
+		single(str("meeting"), str("with boss.")),
+		single(str("break"), str("lunch.")),
+	)
+
+	pairs, err := ParsePairs(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pairs) != 3 || pairs[1].Key.String() != "meeting" || pairs[1].Value.String() != "with boss." {
+		t.Errorf("expecting repeated keys kept, got: %v", pairs)
+	}
+	if got := FormatPairs(pairs); !got.Equal(input) {
+		t.Errorf("round trip: expecting %v, got: %v", input, got)
+	}
+
+	if _, err := ParsePairs(yamlot.NewSequence(yamlot.NewNull())); !errors.Is(err, ErrInvalidPairs) {
+		t.Errorf("expecting ErrInvalidPairs, got: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Package schema implements types from the YAML type repository
+// (https://yaml.org/type/), for use by the decoder and the encoder.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// TagTimestamp is the tag of timestamp scalars.
+const TagTimestamp = "tag:yaml.org,2002:timestamp"
+
+// ErrInvalidTimestamp reports a scalar that does not match any timestamp form.
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// timestampForm follows https://yaml.org/type/timestamp.html.
+var timestampForm = regexp.MustCompile(`^([0-9]{4})-([0-9]{1,2})-([0-9]{1,2})` + // date
+	`(?:(?:[Tt]|[ \t]+)([0-9]{1,2}):([0-9]{2}):([0-9]{2})` + // time
+	`(?:\.([0-9]*))?` + // fraction
+	`(?:[ \t]*(Z|([-+])([0-9]{1,2})(?::([0-9]{2}))?))?)?$`) // time zone
+
+// ParseTimestamp decodes a timestamp scalar such as "2001-12-14",
+// "2001-12-14t21:59:43.10-05:00" or "2001-12-14 21:59:43.10 -5".
+// A timestamp without time zone is in UTC. The canonical date-only form
+// requires two-digit month and day.
+func ParseTimestamp(s string) (time.Time, error) {
+	m := timestampForm.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimestamp, s)
+	}
+	if m[4] == "" && (len(m[2]) != 2 || len(m[3]) != 2) {
+		return time.Time{}, fmt.Errorf("%w: %q: date needs two-digit month and day", ErrInvalidTimestamp, s)
+	}
+
+	year, month, day := atoi(m[1]), atoi(m[2]), atoi(m[3])
+	var hour, minute, second, nsec int
+	if m[4] != "" {
+		hour, minute, second = atoi(m[4]), atoi(m[5]), atoi(m[6])
+	}
+	if fraction := m[7]; fraction != "" {
+		if len(fraction) > 9 {
+			fraction = fraction[:9] // beyond nanosecond precision
+		}
+		nsec = atoi(fraction)
+		for range 9 - len(fraction) {
+			nsec *= 10
+		}
+	}
+
+	loc := time.UTC
+	if m[9] != "" {
+		zoneHour, zoneMinute := atoi(m[10]), atoi(m[11])
+		if zoneHour > 23 || zoneMinute > 59 {
+			return time.Time{}, fmt.Errorf("%w: %q: time zone out of range", ErrInvalidTimestamp, s)
+		}
+		offset := zoneHour*3600 + zoneMinute*60
+		if m[9] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, nsec, loc)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day ||
+		t.Hour() != hour || t.Minute() != minute || t.Second() != second {
+		return time.Time{}, fmt.Errorf("%w: %q: field out of range", ErrInvalidTimestamp, s)
+	}
+	return t, nil
+}
+
+// FormatTimestamp encodes t in the canonical form, such as
+// "2001-12-15T02:59:43.1Z", or as date only, such as "2002-12-14",
+// for midnight in UTC.
+func FormatTimestamp(t time.Time) string {
+	if t.Location() == time.UTC && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// atoi converts a string of at most 9 digits, already matched by a regexp.
+func atoi(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
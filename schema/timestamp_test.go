@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var timestampTestTable = []struct {
+	input    string
+	expected time.Time
+}{
+	// examples from https://yaml.org/type/timestamp.html
+	{"2001-12-15T02:59:43.1Z", time.Date(2001, 12, 15, 2, 59, 43, 100000000, time.UTC)},
+	{"2001-12-14t21:59:43.10-05:00", time.Date(2001, 12, 15, 2, 59, 43, 100000000, time.UTC)},
+	{"2001-12-14 21:59:43.10 -5", time.Date(2001, 12, 15, 2, 59, 43, 100000000, time.UTC)},
+	{"2001-12-15 2:59:43.10", time.Date(2001, 12, 15, 2, 59, 43, 100000000, time.UTC)},
+	{"2002-12-14", time.Date(2002, 12, 14, 0, 0, 0, 0, time.UTC)},
+
+	{"2001-1-2T03:04:05Z", time.Date(2001, 1, 2, 3, 4, 5, 0, time.UTC)},
+	{"2001-12-14T21:59:43.123456789123Z", time.Date(2001, 12, 14, 21, 59, 43, 123456789, time.UTC)},
+	{"2001-12-14T21:59:43.Z", time.Date(2001, 12, 14, 21, 59, 43, 0, time.UTC)},
+	{"2001-12-14 21:59:43 +05:30", time.Date(2001, 12, 14, 16, 29, 43, 0, time.UTC)},
+	{"2001-12-14T21:59:43-23:59", time.Date(2001, 12, 15, 21, 58, 43, 0, time.UTC)},
+}
+
+// go test -count 1 -run '^TestParseTimestamp$' ./...
+func TestParseTimestamp(t *testing.T) {
+	for i, data := range timestampTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(timestampTestTable), data.input)
+
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTimestamp(data.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(data.expected) {
+				t.Errorf("expecting %v, got: %v", data.expected, got)
+			}
+		})
+	}
+}
+
+var invalidTimestampTestTable = []string{
+	"",
+	"2001",
+	"2001-12",
+	"2001-1-2",
+	"01-12-14",
+	"2001-13-14",
+	"2001-02-30",
+	"2001-12-14T25:00:00Z",
+	"2001-12-14T21:60:00Z",
+	"2001-12-14T21:59",
+	"2001-12-14T21:59:43+",
+	"2001-12-14 x",
+	"2001-12-14T21:59:43+99:99",
+	"2001-12-14T21:59:43-25",
+	"2001-12-14T21:59:43+05:60",
+}
+
+// go test -count 1 -run '^TestParseTimestampInvalid$' ./...
+func TestParseTimestampInvalid(t *testing.T) {
+	for i, input := range invalidTimestampTestTable {
+		name := fmt.Sprintf("%02d of %02d: %q", i+1, len(invalidTimestampTestTable), input)
+
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTimestamp(input)
+			if !errors.Is(err, ErrInvalidTimestamp) {
+				t.Errorf("expecting ErrInvalidTimestamp, got: %v", err)
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestFormatTimestamp$' ./...
+func TestFormatTimestamp(t *testing.T) {
+	for _, input := range []string{"2002-12-14", "2001-12-15T02:59:43.1Z", "2001-12-14T21:59:43.1-05:00"} {
+		ts, err := ParseTimestamp(input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", input, err)
+		}
+		if got := FormatTimestamp(ts); got != input {
+			t.Errorf("expecting %s, got: %s", input, got)
+		}
+	}
+}
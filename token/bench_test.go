@@ -0,0 +1,55 @@
+package token
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// go test -run '^$' -bench '^BenchmarkTokenizer$' -benchmem ./token
+func BenchmarkTokenizer(b *testing.B) {
+	files, err := filepath.Glob("../samples/*.yaml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			b.Fatal(err)
+		}
+		name := filepath.Base(f)
+		// large input to mimic multi-megabyte generated manifests
+		large := bytes.Repeat(data, 10000)
+		b.Run(name, func(b *testing.B) {
+			benchmarkTokenizer(b, data, newReaderTokenizer)
+		})
+		b.Run(name+"-x10000", func(b *testing.B) {
+			benchmarkTokenizer(b, large, newReaderTokenizer)
+		})
+		b.Run(name+"-bytes", func(b *testing.B) {
+			benchmarkTokenizer(b, data, NewBytesTokenizer)
+		})
+		b.Run(name+"-bytes-x10000", func(b *testing.B) {
+			benchmarkTokenizer(b, large, NewBytesTokenizer)
+		})
+	}
+}
+
+func newReaderTokenizer(data []byte) *Tokenizer {
+	return NewTokenizer(bytes.NewReader(data), false)
+}
+
+func benchmarkTokenizer(b *testing.B, data []byte, newTokenizer func([]byte) *Tokenizer) {
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		tokenizer := newTokenizer(data)
+		for {
+			_, err := tokenizer.NextToken()
+			if err != nil {
+				break
+			}
+		}
+	}
+}
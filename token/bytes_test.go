@@ -0,0 +1,36 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// go test -count 1 -run '^TestBytesTokenizer$' ./...
+func TestBytesTokenizer(t *testing.T) {
+
+	table := append(slices.Clone(tokenizerTestTable), indentTestTable...)
+
+	for i, data := range table {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(table), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			fromReader := NewTokenizer(strings.NewReader(data.input), isDebugEnabled())
+			fromBytes := NewBytesTokenizer([]byte(data.input))
+			for {
+				tk1, err1 := fromReader.NextToken()
+				tk2, err2 := fromBytes.NextToken()
+				if tk1 != tk2 {
+					t.Errorf("reader token %v differs from bytes token %v", tk1, tk2)
+				}
+				if err1 != err2 {
+					t.Errorf("reader error %v differs from bytes error %v", err1, err2)
+				}
+				if err1 != nil || err2 != nil {
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type commentTest struct {
+	name     string
+	input    string
+	expected []Token // with KeepComments enabled
+}
+
+var commentTestTable = []commentTest{
+	{"hash-inside-scalar", "- url: http://x/#frag\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "url: http://x/#frag"}, {Type: TokenNewLine},
+	}},
+	{"hash-glued-to-scalar", "a#b\n", []Token{
+		{Type: TokenPlainScalar, Value: "a#b"}, {Type: TokenNewLine},
+	}},
+	{"comment-after-scalar", "a #comment\n", []Token{
+		{Type: TokenPlainScalar, Value: "a"}, {Type: TokenComment, Value: "#comment"}, {Type: TokenNewLine},
+	}},
+	{"comment-after-tab", "- a\t# comment\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "a"}, {Type: TokenComment, Value: "# comment"},
+		{Type: TokenNewLine},
+	}},
+	{"comment-line", "# top\n- a\n", []Token{
+		{Type: TokenComment, Value: "# top"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+	}},
+	{"comment-indented", "  # indented\n- a\n", []Token{
+		{Type: TokenComment, Value: "# indented"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+	}},
+	{"comment-after-dash", "- # empty\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: ""}, {Type: TokenComment, Value: "# empty"},
+		{Type: TokenNewLine},
+	}},
+	{"comment-ends-fold", "- foo\n  # c\n  bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo"}, {Type: TokenNewLine},
+		{Type: TokenComment, Value: "# c"}, {Type: TokenNewLine},
+		{Type: TokenIndent}, {Type: TokenPlainScalar, Value: "bar"}, {Type: TokenNewLine}, {Type: TokenDedent},
+	}},
+	{"comment-no-newline", "a # c", []Token{
+		{Type: TokenPlainScalar, Value: "a"}, {Type: TokenComment, Value: "# c"},
+	}},
+	{"trailing-blanks-trimmed", "- a  \t\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+	}},
+	{"inner-blanks-kept", "- a  b\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "a  b"}, {Type: TokenNewLine},
+	}},
+	{"double-dash-trailing-blank", "-- \n", []Token{
+		{Type: TokenPlainScalar, Value: "--"}, {Type: TokenNewLine},
+	}},
+}
+
+// go test -count 1 -run '^TestComment$' ./...
+func TestComment(t *testing.T) {
+	for i, data := range commentTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(commentTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			// comments are discarded by default
+			var discarded []Token
+			for _, tk := range data.expected {
+				if tk.Type != TokenComment {
+					discarded = append(discarded, tk)
+				}
+			}
+
+			for _, keep := range []bool{true, false} {
+				expected := data.expected
+				if !keep {
+					expected = discarded
+				}
+				options := Options{KeepComments: keep}
+				fromReader := NewTokenizerWithOptions(strings.NewReader(data.input), options)
+				fromBytes := NewBytesTokenizerWithOptions([]byte(data.input), options)
+				for _, tokenizer := range []*Tokenizer{fromReader, fromBytes} {
+					var tokens []Token
+					for tk, err := range tokenizer.All() {
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						if raw := data.input[tk.Offset:tk.EndOffset]; tk.Type == TokenPlainScalar || tk.Type == TokenComment {
+							if raw != tk.Value {
+								t.Errorf("token %s: raw text %q", tk.String(), raw)
+							}
+						}
+						tokens = append(tokens, tk)
+					}
+					if !slices.EqualFunc(expected, tokens, TokenEqual) {
+						t.Errorf("keep comments=%t wrong:\nexpected:%v\n     got:%v",
+							keep, formatTokens(expected), formatTokens(tokens))
+					}
+				}
+			}
+		})
+	}
+}
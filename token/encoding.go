@@ -0,0 +1,197 @@
+package token
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidEncoding reports input that is not valid in its detected encoding.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// encoding identifies a character encoding allowed by YAML.
+type encoding int
+
+// Encodings detected from byte order mark or from null byte patterns.
+const (
+	encodingUTF8 encoding = iota
+	encodingUTF16BE
+	encodingUTF16LE
+	encodingUTF32BE
+	encodingUTF32LE
+)
+
+var encodingName = []string{
+	"UTF-8",
+	"UTF-16BE",
+	"UTF-16LE",
+	"UTF-32BE",
+	"UTF-32LE",
+}
+
+func (e encoding) String() string {
+	return encodingName[e]
+}
+
+// detectEncoding detects the encoding of a stream from its first bytes,
+// following the YAML 1.2 spec (section 5.2).
+// It returns the encoding and the size of the byte order mark, if any.
+func detectEncoding(head []byte) (encoding, int) {
+	switch {
+	case bytes.HasPrefix(head, []byte{0x00, 0x00, 0xFE, 0xFF}):
+		return encodingUTF32BE, 4
+	case len(head) >= 4 && head[0] == 0x00 && head[1] == 0x00 && head[2] == 0x00:
+		return encodingUTF32BE, 0
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE, 0x00, 0x00}):
+		return encodingUTF32LE, 4
+	case len(head) >= 4 && head[1] == 0x00 && head[2] == 0x00 && head[3] == 0x00:
+		return encodingUTF32LE, 0
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return encodingUTF16BE, 2
+	case len(head) >= 2 && head[0] == 0x00:
+		return encodingUTF16BE, 0
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return encodingUTF16LE, 2
+	case len(head) >= 2 && head[1] == 0x00:
+		return encodingUTF16LE, 0
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return encodingUTF8, 3
+	}
+	return encodingUTF8, 0
+}
+
+// transcoder converts UTF-16 or UTF-32 input into UTF-8.
+type transcoder struct {
+	reader  io.Reader
+	enc     encoding
+	order   binary.ByteOrder
+	unit    [4]byte
+	out     [utf8.UTFMax]byte
+	pending []byte // UTF-8 bytes not yet returned
+	err     error
+}
+
+func newTranscoder(r io.Reader, enc encoding) *transcoder {
+	var order binary.ByteOrder = binary.BigEndian
+	if enc == encodingUTF16LE || enc == encodingUTF32LE {
+		order = binary.LittleEndian
+	}
+	return &transcoder{reader: r, enc: enc, order: order}
+}
+
+func (d *transcoder) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			c := copy(p[n:], d.pending)
+			d.pending = d.pending[c:]
+			n += c
+			continue
+		}
+		if d.err != nil {
+			break
+		}
+		var ch rune
+		ch, d.err = d.decodeRune()
+		if d.err == nil {
+			d.pending = utf8.AppendRune(d.out[:0], ch)
+		}
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, d.err
+}
+
+func (d *transcoder) decodeRune() (rune, error) {
+	if d.enc == encodingUTF32BE || d.enc == encodingUTF32LE {
+		u, err := d.readUnit(4)
+		if err != nil {
+			return 0, err
+		}
+		ch := rune(d.order.Uint32(d.unit[:4]))
+		if u > utf8.MaxRune || utf16.IsSurrogate(ch) {
+			return 0, fmt.Errorf("%w: invalid %s code point 0x%X", ErrInvalidEncoding, d.enc, u)
+		}
+		return ch, nil
+	}
+	u1, err := d.readUnit(2)
+	if err != nil {
+		return 0, err
+	}
+	ch := rune(u1)
+	if !utf16.IsSurrogate(ch) {
+		return ch, nil
+	}
+	u2, err := d.readUnit(2)
+	if err == io.EOF {
+		err = fmt.Errorf("%w: truncated %s surrogate pair", ErrInvalidEncoding, d.enc)
+	}
+	if err != nil {
+		return 0, err
+	}
+	ch = utf16.DecodeRune(ch, rune(u2))
+	if ch == utf8.RuneError {
+		return 0, fmt.Errorf("%w: invalid %s surrogate pair 0x%04X 0x%04X", ErrInvalidEncoding, d.enc, u1, u2)
+	}
+	return ch, nil
+}
+
+// readUnit reads one code unit of size bytes.
+func (d *transcoder) readUnit(size int) (uint32, error) {
+	_, err := io.ReadFull(d.reader, d.unit[:size])
+	if err == io.ErrUnexpectedEOF {
+		return 0, fmt.Errorf("%w: truncated %s code unit", ErrInvalidEncoding, d.enc)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if size == 2 {
+		return uint32(d.order.Uint16(d.unit[:2])), nil
+	}
+	return d.order.Uint32(d.unit[:4]), nil
+}
+
+// ToUTF8 returns input as the UTF-8 text the tokenizer scans.
+// UTF-16 and UTF-32 input is transcoded and loses its byte order mark;
+// UTF-8 input is returned unchanged. Token offsets from a bytes tokenizer
+// over the result refer to the result.
+func ToUTF8(input []byte) ([]byte, error) {
+	enc, bom := detectEncoding(input)
+	if enc == encodingUTF8 {
+		return input, nil
+	}
+	return io.ReadAll(newTranscoder(bytes.NewReader(input[bom:]), enc))
+}
+
+// newReaderSource detects the encoding of input, strips the byte order
+// mark, and transcodes UTF-16 and UTF-32 into UTF-8.
+// For UTF-8 it returns the size of the stripped byte order mark.
+func newReaderSource(input io.Reader) (readerSource, int) {
+	reader := bufio.NewReader(input)
+	head, _ := reader.Peek(4) // errors are reported by the first read
+	enc, bom := detectEncoding(head)
+	_, _ = reader.Discard(bom) // already peeked
+	if enc == encodingUTF8 {
+		return readerSource{reader}, bom
+	}
+	return readerSource{bufio.NewReader(newTranscoder(reader, enc))}, 0
+}
+
+// newBytesSource detects the encoding of input and transcodes UTF-16 and
+// UTF-32 into UTF-8. For UTF-8 the byte order mark is skipped but kept
+// in the text, so that offsets refer to the original input.
+// It returns the source and the initial offset.
+func newBytesSource(input []byte) (*bytesSource, int) {
+	enc, bom := detectEncoding(input)
+	if enc == encodingUTF8 {
+		return &bytesSource{text: input, pos: bom}, bom
+	}
+	text, err := ToUTF8(input)
+	return &bytesSource{text: text, err: err}, 0
+}
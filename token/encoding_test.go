@@ -0,0 +1,277 @@
+package token
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+const encodingInput = "- ação\n- 日本\n- 😀\n"
+
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = order.AppendUint16(out, 0xFEFF)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = order.AppendUint16(out, u)
+	}
+	return out
+}
+
+func encodeUTF32(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = order.AppendUint32(out, 0xFEFF)
+	}
+	for _, r := range s {
+		out = order.AppendUint32(out, uint32(r))
+	}
+	return out
+}
+
+var encodingTestTable = []struct {
+	name  string
+	input []byte
+}{
+	{"utf-8", []byte(encodingInput)},
+	{"utf-8-bom", append([]byte{0xEF, 0xBB, 0xBF}, encodingInput...)},
+	{"utf-16be-bom", encodeUTF16(encodingInput, binary.BigEndian, true)},
+	{"utf-16le-bom", encodeUTF16(encodingInput, binary.LittleEndian, true)},
+	{"utf-16be", encodeUTF16(encodingInput, binary.BigEndian, false)},
+	{"utf-16le", encodeUTF16(encodingInput, binary.LittleEndian, false)},
+	{"utf-32be-bom", encodeUTF32(encodingInput, binary.BigEndian, true)},
+	{"utf-32le-bom", encodeUTF32(encodingInput, binary.LittleEndian, true)},
+	{"utf-32be", encodeUTF32(encodingInput, binary.BigEndian, false)},
+	{"utf-32le", encodeUTF32(encodingInput, binary.LittleEndian, false)},
+}
+
+// go test -count 1 -run '^TestEncoding$' ./...
+func TestEncoding(t *testing.T) {
+
+	expected := []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "ação"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "日本"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "😀"}, {Type: TokenNewLine},
+	}
+
+	for i, data := range encodingTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(encodingTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			fromReader := NewTokenizer(strings.NewReader(string(data.input)), isDebugEnabled())
+			fromBytes := NewBytesTokenizer(data.input)
+			for _, tokenizer := range []*Tokenizer{fromReader, fromBytes} {
+				var tokens []Token
+				for tk, err := range tokenizer.All() {
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					tokens = append(tokens, tk)
+				}
+				if len(tokens) != len(expected) {
+					t.Errorf("wrong:\nexpected:%v\n     got:%v",
+						formatTokens(expected), formatTokens(tokens))
+					return
+				}
+				for j, tk := range tokens {
+					if !TokenEqual(tk, expected[j]) || tk.Line != j/3+1 {
+						t.Errorf("token %d: expected %s at line %d, got %s at line %d",
+							j, expected[j].String(), j/3+1, tk.String(), tk.Line)
+					}
+				}
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestToUTF8$' ./...
+func TestToUTF8(t *testing.T) {
+	for i, data := range encodingTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(encodingTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			text, err := ToUTF8(data.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := strings.TrimPrefix(string(text), "\uFEFF") // UTF-8 BOM is kept
+			if expected != encodingInput {
+				t.Errorf("expecting %q, got: %q", encodingInput, text)
+			}
+			for tk, err := range NewBytesTokenizer(text).All() {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if raw := string(text[tk.Offset:tk.EndOffset]); tk.Type == TokenPlainScalar && raw != tk.Value {
+					t.Errorf("expecting raw text %q, got: %q", tk.Value, raw)
+				}
+			}
+		})
+	}
+
+	if _, err := ToUTF8([]byte{0xFF, 0xFE, 'a', 0x00, 'b'}); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("expecting ErrInvalidEncoding for truncated UTF-16, got: %v", err)
+	}
+}
+
+// go test -count 1 -run '^TestEncodingBOMOffset$' ./...
+func TestEncodingBOMOffset(t *testing.T) {
+	input := "\uFEFF- a\n"
+	for _, tokenizer := range []*Tokenizer{
+		NewTokenizer(strings.NewReader(input), isDebugEnabled()),
+		NewBytesTokenizer([]byte(input)),
+	} {
+		tk, err := tokenizer.NextToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tk.Type != TokenDash || tk.Column != 1 || tk.Offset != 3 {
+			t.Errorf("expecting dash at column 1 offset 3, got %s at column %d offset %d",
+				tk.String(), tk.Column, tk.Offset)
+		}
+		tk, err = tokenizer.NextToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw := input[tk.Offset:tk.EndOffset]; raw != "a" {
+			t.Errorf("expecting raw scalar text 'a', got %q", raw)
+		}
+	}
+}
+
+var invalidEncodingTestTable = []struct {
+	name     string
+	input    []byte
+	position string
+}{
+	{"utf-8-invalid-byte", []byte("- a\xffb\n"), "line 1, column 4"},
+	{"utf-8-invalid-second-line", []byte("- a\n\xc3"), "line 2, column 1"},
+	{"utf-16le-truncated", append(encodeUTF16("- a", binary.LittleEndian, true), 'b'), "line 1, column 4"},
+	{"utf-16be-lone-surrogate", append(encodeUTF16("- a\n", binary.BigEndian, true), 0xDC, 0x00), "line 2, column 1"},
+	{"utf-32le-out-of-range", append(encodeUTF32("-", binary.LittleEndian, true), 0x00, 0x00, 0x11, 0x00), "line 1, column 2"},
+}
+
+// go test -count 1 -run '^TestInvalidEncoding$' ./...
+func TestInvalidEncoding(t *testing.T) {
+	for i, data := range invalidEncodingTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(invalidEncodingTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			fromReader := NewTokenizer(strings.NewReader(string(data.input)), isDebugEnabled())
+			fromBytes := NewBytesTokenizer(data.input)
+			for _, tokenizer := range []*Tokenizer{fromReader, fromBytes} {
+				var found bool
+				for tk, err := range tokenizer.All() {
+					if err == nil {
+						continue
+					}
+					found = true
+					if !errors.Is(err, ErrInvalidEncoding) {
+						t.Errorf("expecting invalid encoding error, got: %v", err)
+					}
+					if !strings.Contains(err.Error(), data.position) {
+						t.Errorf("expecting error at %s, got: %v", data.position, err)
+					}
+					if tk.Type != TokenError {
+						t.Errorf("expecting error token, got: %s", tk.String())
+					}
+				}
+				if !found {
+					t.Error("expecting error, got none")
+				}
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestInvalidEncodingResync$' ./...
+func TestInvalidEncodingResync(t *testing.T) {
+	const input = "a\xffb\n- c\n"
+
+	expected := []Token{
+		{Type: TokenPlainScalar, Value: "a", Offset: 0, EndOffset: 1},
+		{Type: TokenError, Offset: 2, EndOffset: 2},
+		{Type: TokenPlainScalar, Value: "b", Offset: 2, EndOffset: 3},
+		{Type: TokenNewLine, Value: "\\n", Offset: 3, EndOffset: 4},
+		{Type: TokenDash, Value: "-", Offset: 4, EndOffset: 5},
+		{Type: TokenPlainScalar, Value: "c", Offset: 6, EndOffset: 7},
+		{Type: TokenNewLine, Value: "\\n", Offset: 7, EndOffset: 8},
+		{Type: TokenEOF, Offset: 8, EndOffset: 8},
+	}
+
+	for _, tokenizer := range []*Tokenizer{
+		NewTokenizer(strings.NewReader(input), isDebugEnabled()),
+		NewBytesTokenizer([]byte(input)),
+	} {
+		tokens := drainTokens(tokenizer, 2*len(expected))
+		if len(tokens) != len(expected) {
+			t.Errorf("wrong:\nexpected:%v\n     got:%v", formatTokens(expected), formatTokens(tokens))
+			continue
+		}
+		for i, tk := range tokens {
+			e := expected[i]
+			if tk.Type != e.Type || tk.Value != e.Value || tk.Offset != e.Offset || tk.EndOffset != e.EndOffset {
+				t.Errorf("token %d: expecting %s [%d:%d], got %s [%d:%d]",
+					i, e.String(), e.Offset, e.EndOffset, tk.String(), tk.Offset, tk.EndOffset)
+			}
+		}
+	}
+}
+
+// drainTokens returns the tokens up to EOF, going on after errors.
+// It stops after limit tokens.
+func drainTokens(tokenizer *Tokenizer, limit int) []Token {
+	var tokens []Token
+	for len(tokens) < limit {
+		tk, err := tokenizer.NextToken()
+		tokens = append(tokens, tk)
+		if err == io.EOF {
+			break
+		}
+	}
+	return tokens
+}
+
+var encodingEndTestTable = []struct {
+	name     string
+	input    []byte
+	expected string
+}{
+	{"utf-16le-truncated", []byte{0xFF, 0xFE, 'a', 0x00, 'b'}, "PLAIN-SCALAR(a),ERROR,EOF"},
+	{"utf-16be-truncated-line", append(encodeUTF16("- a\n", binary.BigEndian, true), 0x00), "DASH,PLAIN-SCALAR(a),NEWLINE,ERROR,EOF"},
+	{"utf-32le-out-of-range", append(encodeUTF32("a", binary.LittleEndian, true), 0x00, 0x00, 0x11, 0x00), "PLAIN-SCALAR(a),ERROR,EOF"},
+	{"utf-8-invalid-in-comment", []byte("# a\xffb\n- c\n"), "ERROR,NEWLINE,DASH,PLAIN-SCALAR(c),NEWLINE,EOF"},
+}
+
+// go test -count 1 -run '^TestInvalidEncodingEnd$' ./...
+func TestInvalidEncodingEnd(t *testing.T) {
+	for i, data := range encodingEndTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(encodingEndTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			options := Options{KeepComments: true}
+			for _, tokenizer := range []*Tokenizer{
+				NewTokenizerWithOptions(strings.NewReader(string(data.input)), options),
+				NewBytesTokenizerWithOptions(data.input, options),
+			} {
+				tokens := drainTokens(tokenizer, 20)
+				if got := formatTokens(tokens); got != data.expected {
+					t.Errorf("expecting %s, got: %s", data.expected, got)
+				}
+				expectEOF(t, tokenizer) // EOF again
+			}
+		})
+	}
+
+	// a failing reader reports its error once
+	tokens := drainTokens(NewTokenizer(&errReader{}, isDebugEnabled()), 20)
+	if got := formatTokens(tokens); got != "ERROR,EOF" {
+		t.Errorf("failing reader: expecting ERROR,EOF, got: %s", got)
+	}
+}
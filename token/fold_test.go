@@ -0,0 +1,136 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var foldTestTable = []tokenizerTest{
+	{"fold-continuation", "- foo\n  bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo bar"}, {Type: TokenNewLine},
+	}},
+	{"fold-three-lines", "- foo\n  bar\n   baz\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo bar baz"}, {Type: TokenNewLine},
+	}},
+	{"fold-empty-line", "- foo\n\n  bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo\nbar"}, {Type: TokenNewLine},
+	}},
+	{"fold-two-empty-lines", "- foo\n  \n\n  bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo\n\nbar"}, {Type: TokenNewLine},
+	}},
+	{"fold-trailing-blanks", "- foo \t\n  bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo bar"}, {Type: TokenNewLine},
+	}},
+	{"fold-tab-separation", "- foo\n \tbar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo bar"}, {Type: TokenNewLine},
+	}},
+	{"fold-crlf", "- foo\r\n  bar\r\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo bar"}, {Type: TokenNewLine},
+	}},
+	{"fold-then-next-entry", "- foo\n  bar\n- baz\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo bar"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "baz"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-same-indent", "- foo\n- bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "bar"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-empty-lines-kept", "- foo\n\n- bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo"}, {Type: TokenNewLine},
+		{Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "bar"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-indented-block", " foo\n bar\n", []Token{
+		{Type: TokenIndent}, {Type: TokenPlainScalar, Value: "foo"}, {Type: TokenNewLine},
+		{Type: TokenPlainScalar, Value: "bar"}, {Type: TokenNewLine}, {Type: TokenDedent},
+	}},
+	{"fold-indented-block", " foo\n  bar\n", []Token{
+		{Type: TokenIndent}, {Type: TokenPlainScalar, Value: "foo bar"}, {Type: TokenNewLine},
+		{Type: TokenDedent},
+	}},
+	{"no-fold-empty-scalar", "- \n  bar\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: ""}, {Type: TokenNewLine},
+		{Type: TokenIndent}, {Type: TokenPlainScalar, Value: "bar"}, {Type: TokenNewLine},
+		{Type: TokenDedent},
+	}},
+	{"no-fold-trailing-blank-lines", "- foo\n  \n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "foo"}, {Type: TokenNewLine},
+		{Type: TokenNewLine},
+	}},
+	{"fold-top-level", "a\nb  \n  c\nd\n\ne", []Token{
+		{Type: TokenPlainScalar, Value: "a b c d\ne"},
+	}},
+	{"fold-top-level-tab", "1st non-empty\n\n 2nd non-empty \n\t3rd non-empty\n", []Token{
+		{Type: TokenPlainScalar, Value: "1st non-empty\n2nd non-empty 3rd non-empty"}, {Type: TokenNewLine},
+	}},
+	{"fold-top-level-after-doc-start", "--- a\nb\n", []Token{
+		{Type: TokenDocStart}, {Type: TokenPlainScalar, Value: "a b"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-top-level-entry", "a\n- b\n", []Token{
+		{Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "b"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-top-level-doc-start", "a\n---\nb\n", []Token{
+		{Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+		{Type: TokenDocStart}, {Type: TokenNewLine},
+		{Type: TokenPlainScalar, Value: "b"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-top-level-doc-end", "a\n...\n", []Token{
+		{Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+		{Type: TokenDocEnd}, {Type: TokenNewLine},
+	}},
+	{"fold-top-level-dashes-without-space", "a\n---b\n", []Token{
+		{Type: TokenPlainScalar, Value: "a ---b"}, {Type: TokenNewLine},
+	}},
+	{"no-fold-after-top-level-entry", "- a\nb\n", []Token{
+		{Type: TokenDash}, {Type: TokenPlainScalar, Value: "a"}, {Type: TokenNewLine},
+		{Type: TokenPlainScalar, Value: "b"}, {Type: TokenNewLine},
+	}},
+}
+
+// go test -count 1 -run '^TestFold$' ./...
+func TestFold(t *testing.T) {
+	for i, data := range foldTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(foldTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			fromReader := NewTokenizer(strings.NewReader(data.input), isDebugEnabled())
+			fromBytes := NewBytesTokenizer([]byte(data.input))
+			for _, tokenizer := range []*Tokenizer{fromReader, fromBytes} {
+				var tokens []Token
+				for tk, err := range tokenizer.All() {
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					tokens = append(tokens, tk)
+				}
+				if !slices.EqualFunc(data.expected, tokens, TokenEqual) {
+					t.Errorf("wrong:\nexpected:%v\n     got:%v",
+						formatTokens(data.expected), formatTokens(tokens))
+				}
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestFoldPosition$' ./...
+func TestFoldPosition(t *testing.T) {
+	const input = "- foo\n\n  bar\n- baz\n"
+	tokens, err := Tokenize([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scalar := tokens[1]
+	if scalar.Line != 1 || scalar.Column != 3 || scalar.EndLine != 3 || scalar.EndColumn != 6 {
+		t.Errorf("wrong scalar span %d:%d-%d:%d", scalar.Line, scalar.Column, scalar.EndLine, scalar.EndColumn)
+	}
+	if raw := input[scalar.Offset:scalar.EndOffset]; raw != "foo\n\n  bar" {
+		t.Errorf("wrong raw scalar text %q", raw)
+	}
+	if dash := tokens[3]; dash.Type != TokenDash || dash.Line != 4 {
+		t.Errorf("expecting dash at line 4, got %s at line %d", dash.String(), dash.Line)
+	}
+}
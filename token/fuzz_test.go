@@ -0,0 +1,103 @@
+package token
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// addFuzzSeeds seeds the corpus with the samples and the hand-written test
+// tables.
+func addFuzzSeeds(f *testing.F) {
+	files, err := filepath.Glob("../samples/*.yaml")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	for _, table := range [][]tokenizerTest{tokenizerTestTable, indentTestTable, foldTestTable} {
+		for _, data := range table {
+			f.Add([]byte(data.input))
+		}
+	}
+	for _, data := range commentTestTable {
+		f.Add([]byte(data.input))
+	}
+	for _, data := range tabTestTable {
+		f.Add([]byte(data.input))
+	}
+	for _, data := range encodingTestTable {
+		f.Add(data.input)
+	}
+	for _, data := range invalidEncodingTestTable {
+		f.Add(data.input)
+	}
+	for _, data := range encodingEndTestTable {
+		f.Add(data.input)
+	}
+}
+
+// go test -run '^$' -fuzz '^FuzzTokenizer$' -fuzztime 30s ./token
+func FuzzTokenizer(f *testing.F) {
+	addFuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fromBytes := checkTokenStream(t, NewBytesTokenizerWithOptions(data, Options{KeepComments: true}), len(data))
+		fromReader := checkTokenStream(t, NewTokenizerWithOptions(bytes.NewReader(data), Options{KeepComments: true}), len(data))
+
+		if len(fromBytes) != len(fromReader) {
+			t.Fatalf("bytes tokenizer produced %d tokens, reader tokenizer %d", len(fromBytes), len(fromReader))
+		}
+		for i := range fromBytes {
+			if fromBytes[i] != fromReader[i] {
+				t.Fatalf("token %d: bytes tokenizer %+v differs from reader tokenizer %+v", i, fromBytes[i], fromReader[i])
+			}
+		}
+	})
+}
+
+// checkTokenStream drains the tokenizer, going on after error tokens, and
+// asserts the stream invariants: the stream ends with TokenEOF within a
+// bound proportional to the input size, errors come with error tokens,
+// and positions never move backwards.
+func checkTokenStream(t *testing.T, tokenizer *Tokenizer, size int) []Token {
+	t.Helper()
+
+	limit := 4*size + 16
+	var tokens []Token
+	var last Token
+	for {
+		tk, err := tokenizer.NextToken()
+		tokens = append(tokens, tk)
+		if len(tokens) > limit {
+			t.Fatalf("tokenizer did not reach EOF after %d tokens", len(tokens))
+		}
+		if tk.EndOffset < tk.Offset || tk.EndLine < tk.Line ||
+			(tk.EndLine == tk.Line && tk.EndColumn < tk.Column) {
+			t.Fatalf("token %v ends before it starts", tk)
+		}
+		if tk.Offset < last.Offset || tk.Line < last.Line ||
+			(tk.Line == last.Line && tk.Column < last.Column) {
+			t.Fatalf("token %v starts before previous token %v", tk, last)
+		}
+		last = tk
+		switch {
+		case err == io.EOF:
+			if tk.Type != TokenEOF {
+				t.Fatalf("io.EOF returned with token %v", tk)
+			}
+			return tokens
+		case tk.Type == TokenEOF:
+			t.Fatalf("EOF token returned with error %v", err)
+		case err != nil && tk.Type != TokenError:
+			t.Fatalf("error %v returned with token %v", err, tk)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package token
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// formatGoldenTokens renders one token per line with its span, type and
+// quoted value, followed by the error that ended the stream, if any.
+func formatGoldenTokens(input []byte) []byte {
+	var buf bytes.Buffer
+	tokenizer := NewBytesTokenizerWithOptions(input, Options{KeepComments: true})
+	for tk, err := range tokenizer.All() {
+		fmt.Fprintf(&buf, "%d:%d-%d:%d %s %q\n", tk.Line, tk.Column, tk.EndLine, tk.EndColumn,
+			tokenTypeName[tk.Type], tk.Value)
+		if err != nil {
+			fmt.Fprintf(&buf, "error: %v\n", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// go test -count 1 -run '^TestGoldenSamples$' ./token
+// go test -count 1 -run '^TestGoldenSamples$' ./token -update
+func TestGoldenSamples(t *testing.T) {
+	files, err := filepath.Glob("../samples/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no samples found")
+	}
+
+	for i, file := range files {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(files), filepath.Base(file))
+
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := formatGoldenTokens(input)
+
+			golden := strings.TrimSuffix(file, ".yaml") + ".tokens"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("tokens differ from %s (run with -update to accept)\ngot:\n%s\nexpected:\n%s",
+					golden, got, expected)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package token
 
 import (
 	"fmt"
-	"io"
 	"slices"
 	"strings"
 	"testing"
@@ -24,6 +23,55 @@ var indentTestTable = []tokenizerTest{
 			{Type: TokenDedent},
 		},
 	},
+	{"indented dash with tab", " -\ta\n",
+		[]Token{
+			{Type: TokenIndent},
+			{Type: TokenDash},
+			{Type: TokenPlainScalar, Value: "a"},
+			{Type: TokenNewLine},
+			{Type: TokenDedent},
+		},
+	},
+	{"indented dash at end of line", " -\n",
+		[]Token{
+			{Type: TokenIndent},
+			{Type: TokenDash},
+			{Type: TokenNewLine},
+			{Type: TokenDedent},
+		},
+	},
+	{"indented dash at end of input", " -",
+		[]Token{
+			{Type: TokenIndent},
+			{Type: TokenDash},
+			{Type: TokenDedent},
+		},
+	},
+	{"nested sequence", "-\n  - a\n",
+		[]Token{
+			{Type: TokenDash},
+			{Type: TokenNewLine},
+			{Type: TokenIndent},
+			{Type: TokenDash},
+			{Type: TokenPlainScalar, Value: "a"},
+			{Type: TokenNewLine},
+			{Type: TokenDedent},
+		},
+	},
+	{"dedent to sequence entry", "-\n  - a\n- b\n",
+		[]Token{
+			{Type: TokenDash},
+			{Type: TokenNewLine},
+			{Type: TokenIndent},
+			{Type: TokenDash},
+			{Type: TokenPlainScalar, Value: "a"},
+			{Type: TokenNewLine},
+			{Type: TokenDedent},
+			{Type: TokenDash},
+			{Type: TokenPlainScalar, Value: "b"},
+			{Type: TokenNewLine},
+		},
+	},
 }
 
 // go test -count 1 -run '^TestIndent$' ./...
@@ -37,11 +85,7 @@ func TestIndent(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			tokenizer := NewTokenizer(strings.NewReader(data.input), debug)
 			var tokens []Token
-			for {
-				tk, err := tokenizer.NextToken()
-				if err == io.EOF && tk.Type == TokenEOF {
-					break
-				}
+			for tk, err := range tokenizer.All() {
 				if err != nil {
 					t.Error(err)
 					return
@@ -0,0 +1,36 @@
+package token
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the remaining tokens.
+// The final EOF token is not yielded.
+// Iteration stops after the first error.
+func (t *Tokenizer) All() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		for {
+			tk, err := t.NextToken()
+			if err == io.EOF && tk.Type == TokenEOF {
+				return
+			}
+			if !yield(tk, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Tokenize tokenizes input until EOF.
+// On error it returns the tokens found before the error.
+func Tokenize(input []byte) ([]Token, error) {
+	var tokens []Token
+	for tk, err := range NewBytesTokenizer(input).All() {
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, tk)
+	}
+	return tokens, nil
+}
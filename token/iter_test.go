@@ -0,0 +1,57 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// go test -count 1 -run '^TestTokenize$' ./...
+func TestTokenize(t *testing.T) {
+	for i, data := range tokenizerTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(tokenizerTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			tokens, err := Tokenize([]byte(data.input))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !slices.EqualFunc(data.expected, tokens, TokenEqual) {
+				t.Errorf("wrong:\nexpected:%v\n     got:%v",
+					formatTokens(data.expected), formatTokens(tokens))
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestAllBreak$' ./...
+func TestAllBreak(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader(simpleBlockSequence), isDebugEnabled())
+	for range tokenizer.All() {
+		break
+	}
+	tk, err := tokenizer.NextToken()
+	if err != nil {
+		t.Error(err)
+	}
+	if tk.Type != TokenDash {
+		t.Errorf("expecting dash after breaking out of iterator, got: %v", tk)
+	}
+}
+
+// go test -count 1 -run '^TestAllError$' ./...
+func TestAllError(t *testing.T) {
+	tokenizer := NewTokenizer(&errReader{}, isDebugEnabled())
+	var count int
+	for tk, err := range tokenizer.All() {
+		count++
+		if err == nil {
+			t.Errorf("expecting error, got token: %v", tk)
+		}
+	}
+	if count != 1 {
+		t.Errorf("expecting iteration to stop after error, got %d tokens", count)
+	}
+}
@@ -0,0 +1,72 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// go test -count 1 -run '^TestLineBreak$' ./...
+func TestLineBreak(t *testing.T) {
+
+	table := append(slices.Clone(tokenizerTestTable), indentTestTable...)
+
+	for _, lineBreak := range []string{"\r\n", "\r"} {
+		for i, data := range table {
+			name := fmt.Sprintf("%q %02d of %02d: %s", lineBreak, i+1, len(table), data.name)
+
+			t.Run(name, func(t *testing.T) {
+				input := strings.ReplaceAll(data.input, "\n", lineBreak)
+				fromReader := NewTokenizer(strings.NewReader(input), isDebugEnabled())
+				fromBytes := NewBytesTokenizer([]byte(input))
+				for _, tokenizer := range []*Tokenizer{fromReader, fromBytes} {
+					var tokens []Token
+					for tk, err := range tokenizer.All() {
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						if tk.Type == TokenNewLine {
+							if raw := input[tk.Offset:tk.EndOffset]; raw != lineBreak {
+								t.Errorf("newline raw text %q, expected %q", raw, lineBreak)
+							}
+						}
+						tokens = append(tokens, tk)
+					}
+
+					if !slices.EqualFunc(data.expected, tokens, TokenEqual) {
+						t.Errorf("wrong:\nexpected:%v\n     got:%v",
+							formatTokens(data.expected), formatTokens(tokens))
+					}
+
+					expectedBreak := lineBreak
+					if !strings.Contains(data.input, "\n") {
+						expectedBreak = ""
+					}
+					if got := tokenizer.LineBreak(); got != expectedBreak {
+						t.Errorf("line break style %q, expected %q", got, expectedBreak)
+					}
+				}
+			})
+		}
+	}
+}
+
+// go test -count 1 -run '^TestLineBreakValue$' ./...
+func TestLineBreakValue(t *testing.T) {
+	tokens, err := Tokenize([]byte("- a\n- b\r\n- c\r- d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for _, tk := range tokens {
+		if tk.Type == TokenNewLine {
+			values = append(values, tk.Value)
+		}
+	}
+	expected := []string{`\n`, `\r\n`, `\r`}
+	if !slices.Equal(values, expected) {
+		t.Errorf("newline values %q, expected %q", values, expected)
+	}
+}
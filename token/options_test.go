@@ -0,0 +1,106 @@
+package token
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// go test -count 1 -run '^TestOptionsLogger$' ./...
+func TestOptionsLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tokenizer := NewTokenizerWithOptions(strings.NewReader("- a\n"), Options{Logger: logger})
+	for {
+		_, err := tokenizer.NextToken()
+		if err != nil {
+			break
+		}
+	}
+	if !strings.Contains(buf.String(), "tokenBufferPush") {
+		t.Errorf("expecting debug traces in logger, got: %q", buf.String())
+	}
+}
+
+// go test -count 1 -run '^TestOptionsMaxScalarLength$' ./...
+func TestOptionsMaxScalarLength(t *testing.T) {
+	options := Options{MaxScalarLength: 3}
+
+	tokenizer := NewTokenizerWithOptions(strings.NewReader("abc\n"), options)
+	if tk, err := tokenizer.NextToken(); err != nil {
+		t.Errorf("unexpected error: %v token: %v", err, tk)
+	}
+
+	tokenizer = NewTokenizerWithOptions(strings.NewReader("abcd\n"), options)
+	tk, err := tokenizer.NextToken()
+	if err == nil {
+		t.Error("expecting error, got nil")
+	}
+	if tk.Type != TokenError {
+		t.Errorf("expecting error token, got: %v", tk)
+	}
+}
+
+// go test -count 1 -run '^TestOptionsMaxIndentDepth$' ./...
+func TestOptionsMaxIndentDepth(t *testing.T) {
+	const input = "-\n -\n  -\n"
+
+	tokenizer := NewTokenizerWithOptions(strings.NewReader(input), Options{MaxIndentDepth: 1})
+	var found bool
+	for {
+		tk, err := tokenizer.NextToken()
+		if err != nil {
+			found = tk.Type == TokenError
+			break
+		}
+	}
+	if !found {
+		t.Error("expecting indentation depth error")
+	}
+
+	tokenizer = NewTokenizerWithOptions(strings.NewReader(input), Options{MaxIndentDepth: 2})
+	for {
+		tk, err := tokenizer.NextToken()
+		if tk.Type == TokenEOF {
+			break
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			break
+		}
+	}
+}
+
+// go test -count 1 -run '^TestOptionsMaxScalarLengthSkip$' ./...
+func TestOptionsMaxScalarLengthSkip(t *testing.T) {
+	table := []struct {
+		name     string
+		input    string
+		expected string
+		end      int // EndOffset of the error token
+	}{
+		{"line", "abcdef\n- x\n", "ERROR,NEWLINE,DASH,PLAIN-SCALAR(x),NEWLINE,EOF", 6},
+		{"folded", "abc\n def\n- x\n", "ERROR,NEWLINE,DASH,PLAIN-SCALAR(x),NEWLINE,EOF", 8},
+		{"entry", "- abcdef\n- x\n", "DASH,ERROR,NEWLINE,DASH,PLAIN-SCALAR(x),NEWLINE,EOF", 8},
+	}
+	options := Options{MaxScalarLength: 4}
+	for _, data := range table {
+		tokenizers := map[string]*Tokenizer{
+			"reader": NewTokenizerWithOptions(strings.NewReader(data.input), options),
+			"bytes":  NewBytesTokenizerWithOptions([]byte(data.input), options),
+		}
+		for path, tokenizer := range tokenizers {
+			tokens := drainTokens(tokenizer, 20)
+			if got := formatTokens(tokens); got != data.expected {
+				t.Errorf("%s %s: expected %s, got %s", data.name, path, data.expected, got)
+				continue
+			}
+			for _, tk := range tokens {
+				if tk.Type == TokenError && tk.EndOffset != data.end {
+					t.Errorf("%s %s: expected error ending at offset %d, got %d", data.name, path, data.end, tk.EndOffset)
+				}
+			}
+		}
+	}
+}
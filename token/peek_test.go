@@ -0,0 +1,86 @@
+package token
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// go test -count 1 -run '^TestPeek$' ./...
+func TestPeek(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader("- a\n"), isDebugEnabled())
+
+	expected := []TokenType{TokenDash, TokenPlainScalar, TokenNewLine, TokenEOF}
+
+	for i, tt := range expected {
+		tk, err := tokenizer.PeekN(i + 1)
+		if tk.Type != tt {
+			t.Errorf("PeekN(%d): expecting %s, got: %s", i+1, tokenTypeName[tt], tk.String())
+		}
+		if tt == TokenEOF && err != io.EOF {
+			t.Errorf("PeekN(%d): expecting EOF error, got: %v", i+1, err)
+		}
+	}
+
+	// peeking past EOF keeps returning EOF
+	if tk, err := tokenizer.PeekN(len(expected) + 3); tk.Type != TokenEOF || err != io.EOF {
+		t.Errorf("expecting EOF past end, got: %s %v", tk.String(), err)
+	}
+
+	for _, tt := range expected {
+		peek, _ := tokenizer.Peek()
+		tk, _ := tokenizer.NextToken()
+		if peek != tk {
+			t.Errorf("peeked token %v differs from next token %v", peek, tk)
+		}
+		if tk.Type != tt {
+			t.Errorf("expecting %s, got: %s", tokenTypeName[tt], tk.String())
+		}
+	}
+
+	expectEOF(t, tokenizer)
+}
+
+// go test -count 1 -run '^TestPeekError$' ./...
+func TestPeekError(t *testing.T) {
+	tokenizer := NewTokenizer(&errReader{}, isDebugEnabled())
+	tk, err := tokenizer.PeekN(2)
+	if err == nil || tk.Type != TokenError {
+		t.Errorf("expecting error token, got: %v %v", tk, err)
+	}
+	tk, err = tokenizer.NextToken()
+	if err == nil || tk.Type != TokenError {
+		t.Errorf("expecting error token, got: %v %v", tk, err)
+	}
+}
+
+// go test -count 1 -run '^TestPeekInvalid$' ./...
+func TestPeekInvalid(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader("a"), isDebugEnabled())
+	if _, err := tokenizer.PeekN(0); err == nil {
+		t.Error("expecting error for zero peek distance")
+	}
+}
+
+// go test -count 1 -run '^TestPeekIndentedDash$' ./...
+func TestPeekIndentedDash(t *testing.T) {
+	const input = "-\n  - a\n  -  b\n- c\n"
+
+	tokens, err := Tokenize([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenizer := NewTokenizer(strings.NewReader(input), isDebugEnabled())
+	for i := range tokens {
+		tk, _ := tokenizer.PeekN(i + 1)
+		if tk != tokens[i] {
+			t.Errorf("PeekN(%d): expecting %v, got: %v", i+1, tokens[i], tk)
+		}
+	}
+
+	expected := "DASH,NEWLINE,INDENT,DASH,PLAIN-SCALAR(a),NEWLINE,DASH,PLAIN-SCALAR(b),NEWLINE,DEDENT,DASH,PLAIN-SCALAR(c),NEWLINE"
+	if got := formatTokens(tokens); got != expected {
+		t.Errorf("expecting %s, got: %s", expected, got)
+	}
+}
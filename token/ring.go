@@ -0,0 +1,56 @@
+package token
+
+// tokenRing is a FIFO of buffered tokens backed by a ring buffer.
+// Capacity is always a power of two, so indexes wrap with a mask.
+type tokenRing struct {
+	buf  []bufferedToken
+	head int
+	size int
+}
+
+func newTokenRing(capacity int) tokenRing {
+	c := 1
+	for c < capacity {
+		c <<= 1
+	}
+	return tokenRing{buf: make([]bufferedToken, c)}
+}
+
+func (r *tokenRing) len() int {
+	return r.size
+}
+
+func (r *tokenRing) push(b bufferedToken) {
+	if r.size == len(r.buf) {
+		r.grow()
+	}
+	r.buf[(r.head+r.size)&(len(r.buf)-1)] = b
+	r.size++
+}
+
+// shift removes the oldest token.
+// It returns false if the ring is empty.
+func (r *tokenRing) shift() (bufferedToken, bool) {
+	if r.size == 0 {
+		return bufferedToken{}, false
+	}
+	b := r.buf[r.head]
+	r.buf[r.head] = bufferedToken{} // release references
+	r.head = (r.head + 1) & (len(r.buf) - 1)
+	r.size--
+	return b, true
+}
+
+// at returns the i-th oldest token. It must be called with 0 <= i < len().
+func (r *tokenRing) at(i int) bufferedToken {
+	return r.buf[(r.head+i)&(len(r.buf)-1)]
+}
+
+func (r *tokenRing) grow() {
+	buf := make([]bufferedToken, max(2*len(r.buf), 1))
+	for i := range r.size {
+		buf[i] = r.at(i)
+	}
+	r.buf = buf
+	r.head = 0
+}
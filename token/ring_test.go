@@ -0,0 +1,39 @@
+package token
+
+import "testing"
+
+// go test -count 1 -run '^TestTokenRing$' ./...
+func TestTokenRing(t *testing.T) {
+	r := newTokenRing(2)
+
+	if _, ok := r.shift(); ok {
+		t.Error("expecting shift from empty ring to fail")
+	}
+
+	// interleave pushes and shifts to force wrapping and growing
+	var next, expect int
+	for round := range 10 {
+		for range round + 1 {
+			r.push(bufferedToken{token: Token{Line: next}})
+			next++
+		}
+		for range round / 2 {
+			b, ok := r.shift()
+			if !ok {
+				t.Fatalf("round %d: unexpected empty ring", round)
+			}
+			if b.token.Line != expect {
+				t.Errorf("round %d: expecting %d, got %d", round, expect, b.token.Line)
+			}
+			expect++
+		}
+		if r.len() != next-expect {
+			t.Errorf("round %d: expecting length %d, got %d", round, next-expect, r.len())
+		}
+		for i := range r.len() {
+			if line := r.at(i).token.Line; line != expect+i {
+				t.Errorf("round %d: at(%d): expecting %d, got %d", round, i, expect+i, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// source provides runes to the tokenizer.
+type source interface {
+	ReadRune() (rune, int, error)
+	peekByte() (byte, error)
+
+	// peekBytes returns up to n next bytes without consuming them,
+	// fewer at end of input.
+	peekBytes(n int) []byte
+
+	// slice returns the raw input between byte offsets, if the source
+	// holds the input. The result shares memory with the input.
+	slice(from, to int) ([]byte, bool)
+}
+
+// readerSource reads runes from an io.Reader through a bufio.Reader.
+type readerSource struct {
+	*bufio.Reader
+}
+
+func (s readerSource) peekByte() (byte, error) {
+	peek, err := s.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return peek[0], nil
+}
+
+func (s readerSource) peekBytes(n int) []byte {
+	peek, _ := s.Peek(n) // errors are reported by the next read
+	return peek
+}
+
+func (s readerSource) slice(_, _ int) ([]byte, bool) {
+	return nil, false
+}
+
+// bytesSource scans runes directly from in-memory input.
+type bytesSource struct {
+	text []byte
+	pos  int
+	err  error // reported after text is exhausted, instead of io.EOF
+}
+
+func (s *bytesSource) ReadRune() (rune, int, error) {
+	if s.pos >= len(s.text) {
+		if s.err != nil {
+			return 0, 0, s.err
+		}
+		return 0, 0, io.EOF
+	}
+	if c := s.text[s.pos]; c < utf8.RuneSelf {
+		s.pos++
+		return rune(c), 1, nil
+	}
+	ch, size := utf8.DecodeRune(s.text[s.pos:])
+	s.pos += size
+	return ch, size, nil
+}
+
+func (s *bytesSource) peekByte() (byte, error) {
+	if s.pos >= len(s.text) {
+		if s.err != nil {
+			return 0, s.err
+		}
+		return 0, io.EOF
+	}
+	return s.text[s.pos], nil
+}
+
+func (s *bytesSource) peekBytes(n int) []byte {
+	return s.text[s.pos:min(s.pos+n, len(s.text))]
+}
+
+func (s *bytesSource) slice(from, to int) ([]byte, bool) {
+	return s.text[from:to], true
+}
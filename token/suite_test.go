@@ -0,0 +1,415 @@
+package token
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite expected results under testdata")
+
+// suiteDir holds the in-tree snapshot of the yaml-test-suite data.
+const suiteDir = "testdata/yaml-test-suite"
+
+// suiteResults records the outcome of every suite case, one
+// "<status> <name>" per line. A case listed as pass must keep passing.
+const suiteResults = "testdata/yaml-test-suite.results"
+
+// Suite case outcomes. A case is skipped when no real check can be
+// made for it yet.
+const (
+	suitePass = "pass"
+	suiteFail = "fail"
+	suiteSkip = "skip"
+)
+
+type suiteCase struct {
+	name        string
+	input       []byte
+	json        []byte // expected values, nil if absent
+	expectError bool
+}
+
+// loadSuite returns one case per directory holding an in.yaml file.
+// A case expects an error when its directory also holds an error file.
+func loadSuite(t *testing.T) []suiteCase {
+	var cases []suiteCase
+	err := filepath.WalkDir(suiteDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "in.yaml" {
+			return nil
+		}
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		_, errStat := os.Stat(filepath.Join(dir, "error"))
+		expectedJSON, err := os.ReadFile(filepath.Join(dir, "in.json"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		name, err := filepath.Rel(suiteDir, dir)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, suiteCase{
+			name:        filepath.ToSlash(name),
+			input:       input,
+			json:        expectedJSON,
+			expectError: errStat == nil,
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("load yaml-test-suite: %v", err)
+	}
+	return cases
+}
+
+// runSuiteCase checks the tokenizer against the case expectation and
+// returns the outcome.
+//
+// A case is checked only when it holds nothing but block sequences and
+// plain scalars, the subset the tokenizer lexes. Other cases are skipped.
+// An error case passes when the tokenizer reports an error. For a valid
+// case the tokens are composed into values and compared with in.json.
+// A panic or a runaway token stream is a failure.
+//
+// Comparing event trees, and checking where an error case fails, requires
+// test.event files, which the snapshot lacks, and a parser.
+func runSuiteCase(c suiteCase) (status string) {
+	defer func() {
+		if recover() != nil {
+			status = suiteFail
+		}
+	}()
+
+	limit := 4*len(c.input) + 16
+	tokenizer := NewBytesTokenizer(c.input)
+	var tokens []Token
+	var gotError bool
+	for {
+		if len(tokens) > limit {
+			return suiteFail
+		}
+		tk, err := tokenizer.NextToken()
+		if err == io.EOF {
+			break
+		}
+		if tk.Type == TokenError || err != nil {
+			gotError = true
+			continue // scanning goes on after errors
+		}
+		tokens = append(tokens, tk)
+	}
+
+	if !suiteSupported(tokens) {
+		return suiteSkip
+	}
+
+	if c.expectError {
+		if gotError {
+			return suitePass
+		}
+		return suiteFail
+	}
+
+	expected, ok := decodeSuiteJSON(c.json)
+	if !ok {
+		return suiteSkip
+	}
+	if gotError {
+		return suiteFail
+	}
+	got, ok := composeSuiteValues(tokens)
+	if !ok || !reflect.DeepEqual(got, expected) {
+		return suiteFail
+	}
+	return suitePass
+}
+
+// decodeSuiteJSON decodes the stream of JSON values in data. It returns
+// false when there is no data or a value holds an object, since the
+// tokenizer does not lex mappings.
+func decodeSuiteJSON(data []byte) ([]any, bool) {
+	if data == nil {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var values []any
+	for {
+		var v any
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return values, true
+		}
+		if err != nil {
+			return nil, false
+		}
+		v, ok := normalizeSuiteJSON(v)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+}
+
+// normalizeSuiteJSON converts numbers to float64, and rejects objects.
+func normalizeSuiteJSON(v any) (any, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case []any:
+		for i, e := range v {
+			n, ok := normalizeSuiteJSON(e)
+			if !ok {
+				return nil, false
+			}
+			v[i] = n
+		}
+		return v, true
+	case map[string]any:
+		return nil, false
+	}
+	return v, true
+}
+
+// suiteSupported reports whether no plain scalar starts with an indicator
+// of syntax the tokenizer does not lex yet (flow collections, quoted and
+// block scalars, anchors, aliases, tags, directives, explicit keys), or
+// holds a mapping value indicator.
+func suiteSupported(tokens []Token) bool {
+	for _, tk := range tokens {
+		if tk.Type != TokenPlainScalar || tk.Value == "" {
+			continue
+		}
+		if strings.ContainsRune("[]{}\"'&*!|>%@`?,", rune(tk.Value[0])) {
+			return false
+		}
+		if strings.HasSuffix(tk.Value, ":") || strings.Contains(tk.Value, ": ") ||
+			strings.Contains(tk.Value, ":\t") || strings.Contains(tk.Value, ":\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// suiteComposer builds JSON-like values from the tokens of block
+// sequences of plain scalars.
+type suiteComposer struct {
+	tokens []Token
+	pos    int
+	failed bool
+}
+
+// composeSuiteValues returns one value per document.
+func composeSuiteValues(tokens []Token) ([]any, bool) {
+	c := suiteComposer{}
+	for _, tk := range tokens {
+		if tk.Type != TokenNewLine && tk.Type != TokenComment {
+			c.tokens = append(c.tokens, tk)
+		}
+	}
+
+	var docs []any
+	for !c.failed && c.pos < len(c.tokens) {
+		switch c.peek() {
+		case TokenDocEnd:
+			c.pos++
+		case TokenDocStart:
+			c.pos++
+			switch c.peek() {
+			case TokenDocStart, TokenDocEnd, TokenEOF:
+				docs = append(docs, nil)
+			default:
+				docs = append(docs, c.node())
+			}
+		default:
+			docs = append(docs, c.node())
+		}
+	}
+	return docs, !c.failed
+}
+
+// peek returns the type of the next token, TokenEOF past the end.
+func (c *suiteComposer) peek() TokenType {
+	if c.pos >= len(c.tokens) {
+		return TokenEOF
+	}
+	return c.tokens[c.pos].Type
+}
+
+func (c *suiteComposer) node() any {
+	switch c.peek() {
+	case TokenIndent:
+		c.pos++
+		v := c.node()
+		if c.peek() == TokenDedent {
+			c.pos++
+		}
+		return v
+	case TokenDash:
+		seq := []any{}
+		for !c.failed && c.peek() == TokenDash {
+			c.pos++
+			switch c.peek() {
+			case TokenIndent, TokenPlainScalar:
+				seq = append(seq, c.node())
+			default:
+				seq = append(seq, nil)
+			}
+		}
+		return seq
+	case TokenPlainScalar:
+		v := resolveSuiteScalar(c.tokens[c.pos].Value)
+		c.pos++
+		return v
+	}
+	c.failed = true
+	return nil
+}
+
+// resolveSuiteScalar resolves a plain scalar under the YAML 1.2 core
+// schema into its JSON value.
+func resolveSuiteScalar(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o") {
+		if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+			return float64(i)
+		}
+		return s
+	}
+	if s[0] == '-' || s[0] == '+' || s[0] == '.' || (s[0] >= '0' && s[0] <= '9') {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "_xXpP") {
+			return f
+		}
+	}
+	return s
+}
+
+func loadSuiteResults(t *testing.T) map[string]string {
+	results := map[string]string{}
+	f, err := os.Open(suiteResults)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return results
+		}
+		t.Fatalf("open suite results: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		status, name, found := strings.Cut(scanner.Text(), " ")
+		if !found {
+			continue
+		}
+		results[name] = status
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read suite results: %v", err)
+	}
+	return results
+}
+
+func writeSuiteResults(t *testing.T, results map[string]string) {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s %s\n", results[name], name)
+	}
+	if err := os.WriteFile(suiteResults, []byte(sb.String()), 0o644); err != nil {
+		t.Fatalf("write suite results: %v", err)
+	}
+}
+
+// suiteTally counts outcomes of one kind of case.
+type suiteTally struct {
+	pass, fail, skip int
+}
+
+func (s *suiteTally) add(status string) {
+	switch status {
+	case suitePass:
+		s.pass++
+	case suiteFail:
+		s.fail++
+	default:
+		s.skip++
+	}
+}
+
+func (s suiteTally) String() string {
+	checked := s.pass + s.fail
+	if checked == 0 {
+		return fmt.Sprintf("0 checked, %d skipped", s.skip)
+	}
+	return fmt.Sprintf("%d/%d passed (%.1f%%), %d skipped",
+		s.pass, checked, 100*float64(s.pass)/float64(checked), s.skip)
+}
+
+// go test -count 1 -run '^TestYAMLTestSuite$' -v ./token
+// go test -count 1 -run '^TestYAMLTestSuite$' ./token -update
+func TestYAMLTestSuite(t *testing.T) {
+	cases := loadSuite(t)
+	if len(cases) == 0 {
+		t.Fatalf("no yaml-test-suite cases found under %s", suiteDir)
+	}
+
+	expected := loadSuiteResults(t)
+	results := make(map[string]string, len(cases))
+
+	var valid, invalid suiteTally
+	for _, c := range cases {
+		status := runSuiteCase(c)
+		results[c.name] = status
+		if c.expectError {
+			invalid.add(status)
+		} else {
+			valid.add(status)
+		}
+		if *update {
+			continue
+		}
+		if expected[c.name] == suitePass && status != suitePass {
+			t.Errorf("regression: suite case %s used to pass, now %s", c.name, status)
+		}
+		if status != expected[c.name] && expected[c.name] != suitePass {
+			t.Logf("suite case %s changed from %q to %s, rerun with -update", c.name, expected[c.name], status)
+		}
+	}
+
+	t.Logf("yaml-test-suite: valid cases: %v", valid)
+	t.Logf("yaml-test-suite: error cases: %v", invalid)
+
+	if *update {
+		writeSuiteResults(t, results)
+	}
+}
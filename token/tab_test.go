@@ -0,0 +1,95 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var tabTestTable = []struct {
+	name   string
+	input  string
+	column int // column of TabIndentation error, zero for none
+}{
+	{"tab-indented-scalar", "\tvalue\n", 1},
+	{"tab-after-spaces", "  \tvalue\n", 3},
+	{"tab-before-spaces", "\t  value\n", 1},
+	{"tab-indented-dash", "- a\n\t- b\n", 1},
+	{"tab-in-blank-line", "- a\n\t\n- b\n", 0},
+	{"tab-before-comment", "\t# comment\n", 0},
+	{"tab-after-dash", "-\tvalue\n", 0},
+	{"tab-after-dash-and-space", "- \tvalue\n", 0},
+	{"tab-inside-scalar", "- a\tb\n", 0},
+	{"tab-after-doc-start", "---\tvalue\n", 0},
+	{"tab-before-flow-mapping", "\t{}\n", 0},
+	{"tab-before-flow-sequence", "\t[\n\t]\n", 0},
+	{"tab-in-plain-lines", "1st non-empty\n\n 2nd non-empty \n\t3rd non-empty\n", 0},
+}
+
+// go test -count 1 -run '^TestTabIndentation$' ./...
+func TestTabIndentation(t *testing.T) {
+	for i, data := range tabTestTable {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(tabTestTable), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			tokens, err := Tokenize([]byte(data.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found []Token
+			for _, tk := range tokens {
+				if tk.Type == TokenError {
+					found = append(found, tk)
+				}
+			}
+			if data.column == 0 {
+				if len(found) > 0 {
+					t.Errorf("unexpected errors: %v", found)
+				}
+				return
+			}
+			if len(found) != 1 {
+				t.Fatalf("expecting one error, got: %v", found)
+			}
+			tk := found[0]
+			if !strings.HasPrefix(tk.Value, "TabIndentation:") {
+				t.Errorf("expecting TabIndentation error, got: %s", tk.Value)
+			}
+			if tk.Column != data.column {
+				t.Errorf("expecting error at column %d, got: %d", data.column, tk.Column)
+			}
+		})
+	}
+}
+
+// go test -count 1 -run '^TestTabSeparation$' ./...
+func TestTabSeparation(t *testing.T) {
+	tokens, err := Tokenize([]byte("-\t\tvalue\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) < 2 || tokens[1].Value != "value" || tokens[1].Column != 4 {
+		t.Errorf("expecting scalar 'value' at column 4, got: %s", formatTokens(tokens))
+	}
+}
+
+// go test -count 1 -run '^TestTabIndentationLevel$' ./...
+func TestTabIndentationLevel(t *testing.T) {
+	inputs := []string{
+		"\t...\n",
+		"\tvalue\n",
+		"- a\n\t- b\n",
+	}
+	for _, input := range inputs {
+		tokens, err := Tokenize([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tk := range tokens {
+			if tk.Type == TokenIndent || tk.Type == TokenDedent {
+				t.Errorf("input %q: tab must not change indentation: %s", input, formatTokens(tokens))
+				break
+			}
+		}
+	}
+}
@@ -2,20 +2,101 @@
 package token
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode/utf8"
 )
 
 // Tokenizer tokenizes yaml tokens.
 type Tokenizer struct {
-	reader                *bufio.Reader
+	reader                source
 	line                  int
 	column                int
+	offset                int
+	prev                  position // position of last rune read
+	start                 position // start of multi-rune token
 	status                tokenStatus
 	debug                 bool
+	logger                *slog.Logger
+	options               Options
 	indentationLevelStack []int
-	tokenBuffer           []Token
+	tokenBuffer           tokenRing
+	scratch               []byte // reusable buffer for scalar bytes
+	slicing               bool   // source slices values, scratch is needed only for folded scalars
+	copying               bool   // current scalar text is accumulated in scratch
+	scalarStart           position
+	scalarBytes           int   // bytes of current scalar line text, including prefix
+	scalarBlank           bool  // last rune of current scalar text is a blank
+	scalarRoot            bool  // current scalar is a top level node
+	unread                bool  // lastRune must be read again
+	failed                bool  // source reported an error, input ends there
+	scalarErr             error // encoding error that ended the scalar, reported after it
+	scalarLimitErr        error // length limit exceeded, rest of the scalar is skipped
+	lastRune              rune
+	lastSize              int
+	lineBreak             string // last line break read
+	breakStyle            string // first line break read
+	lineBlank             bool   // only blanks read in current line
+	leadingTab            bool   // tab found in leading blanks of current line
+	leadingTabPos         position
+	lineIndent            int      // leading spaces of current line, up to the first tab
+	lineDash              bool     // DASH found in current line
+	pendingBreaks         []Token  // NEWLINE tokens consumed after plain scalar
+	contentEnd            position // past last non-blank rune of plain scalar
+}
+
+// bufferedToken holds a scanned token along with its error.
+type bufferedToken struct {
+	token Token
+	err   error
+}
+
+func (b bufferedToken) result() (Token, error) {
+	if b.token.Type == TokenEOF {
+		return b.token, io.EOF
+	}
+	return b.token, b.err
+}
+
+// Options defines tokenizer options.
+//
+// The tokenizer follows YAML 1.2. There is no spec version option yet:
+// see the README TODO.
+type Options struct {
+	// Logger receives debug traces. Nil disables tracing.
+	Logger *slog.Logger
+
+	// MaxScalarLength limits the size in bytes of a scalar.
+	// Zero means no limit.
+	MaxScalarLength int
+
+	// MaxIndentDepth limits the number of nested indentation levels.
+	// Zero means no limit.
+	MaxIndentDepth int
+
+	// KeepComments enables COMMENT tokens.
+	// By default comments are discarded.
+	KeepComments bool
+}
+
+// position locates a rune in the input.
+// Line and column are 1-based, column counts runes.
+// Offset is 0-based and counts bytes.
+type position struct {
+	line   int
+	column int
+	offset int
+}
+
+// advance moves the position forward by n single-byte runes.
+func (p position) advance(n int) position {
+	return position{line: p.line, column: p.column + n, offset: p.offset + n}
 }
 
 type tokenStatus int
@@ -28,7 +109,6 @@ const (
 	statusOneDash
 	statusTwoDashes
 	statusThreeDashes
-	statusAfterDash
 	statusScalar
 )
 
@@ -40,22 +120,78 @@ var statusName = []string{
 	"StatusOneDash",
 	"StatusTwoDashes",
 	"StatusThreeDashes",
-	"StatusAfterDash",
 	"StatusScalar",
 }
 
 // NewTokenizer creates tokenizer.
+// If debug is true, traces are written to stderr.
 func NewTokenizer(input io.Reader, debug bool) *Tokenizer {
+	var options Options
+	if debug {
+		options.Logger = slog.New(slog.NewTextHandler(os.Stderr,
+			&slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return NewTokenizerWithOptions(input, options)
+}
+
+// NewTokenizerWithOptions creates tokenizer with options.
+//
+// The input encoding is detected as required by the YAML spec. A byte
+// order mark is skipped, and UTF-16 or UTF-32 input is transcoded into
+// UTF-8. For transcoded input, token offsets refer to the UTF-8 text.
+func NewTokenizerWithOptions(input io.Reader, options Options) *Tokenizer {
+	reader, offset := newReaderSource(input)
+	return newTokenizer(reader, offset, options)
+}
+
+// NewBytesTokenizer creates tokenizer that scans input directly.
+// It produces the same tokens as NewTokenizer, but copies token values
+// straight from input instead of accumulating them in a buffer.
+func NewBytesTokenizer(input []byte) *Tokenizer {
+	return NewBytesTokenizerWithOptions(input, Options{})
+}
+
+// NewBytesTokenizerWithOptions creates tokenizer with options that scans
+// input directly. The input is not copied, so it must not be modified
+// while the tokenizer is in use. Token values do not share memory with
+// input, and stay valid after it is modified or reused.
+func NewBytesTokenizerWithOptions(input []byte, options Options) *Tokenizer {
+	reader, offset := newBytesSource(input)
+	return newTokenizer(reader, offset, options)
+}
+
+func newTokenizer(reader source, offset int, options Options) *Tokenizer {
+	logger := options.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	_, slicing := reader.slice(0, 0)
 	return &Tokenizer{
-		reader:                bufio.NewReader(input),
+		reader:                reader,
+		slicing:               slicing,
 		line:                  1,
 		column:                0,
+		offset:                offset,
 		status:                statusBlank,
-		debug:                 debug,
+		lineBlank:             true,
+		debug:                 logger.Enabled(context.Background(), slog.LevelDebug),
+		logger:                logger,
+		options:               options,
 		indentationLevelStack: []int{0}, // start with level 0
+		tokenBuffer:           newTokenRing(8),
 	}
 }
 
+// LineBreak returns the first line break found in the input:
+// "\n", "\r\n" or "\r". It returns an empty string if no line
+// break was found so far.
+//
+// Line breaks are normalized into a single NEWLINE token, whose value is
+// the escaped original line break, such as "\\r\\n".
+func (t *Tokenizer) LineBreak() string {
+	return t.breakStyle
+}
+
 func (t *Tokenizer) indentPush(level int) {
 	t.indentationLevelStack = append(t.indentationLevelStack, level)
 }
@@ -74,87 +210,127 @@ func (t *Tokenizer) indentTop() int {
 	return t.indentationLevelStack[len(t.indentationLevelStack)-1]
 }
 
-func (t *Tokenizer) checkIndent() {
+// startContent checks tabs and indentation when the first content of a
+// line, ch, is found. Content that resumes a line after an error does not
+// start a line.
+func (t *Tokenizer) startContent(ch rune) error {
+	if !t.lineBlank {
+		return nil
+	}
+	t.checkTabIndent(ch)
+	return t.checkIndent()
+}
+
+// checkIndent compares the indentation of current line with the
+// indentation levels. Only spaces indent, a tab never counts.
+func (t *Tokenizer) checkIndent() error {
 	previousIndent := t.indentTop()
-	currentIndent := t.column - 1
+	currentIndent := t.lineIndent
 	if currentIndent > previousIndent {
 		// Emit INDENT (Indentation Increased)
 
+		if limit := t.options.MaxIndentDepth; limit > 0 && len(t.indentationLevelStack) > limit {
+			return fmt.Errorf("indentation depth exceeds limit of %d levels", limit)
+		}
+
 		t.indentPush(currentIndent)
-		t.tokenBufferPush(Token{Type: TokenIndent, Line: t.line, Column: t.column})
+		t.tokenBufferPush(newToken(TokenIndent, "", t.prev, t.prev))
 	} else if currentIndent < previousIndent {
 		// Emit DEDENT(s) (Indentation Decreased)
 
 		for len(t.indentationLevelStack) > 1 && currentIndent < t.indentTop() {
 			t.indentPop()
-			t.tokenBufferPush(Token{Type: TokenDedent, Line: t.line, Column: t.column})
+			t.tokenBufferPush(newToken(TokenDedent, "", t.prev, t.prev))
 		}
 
 		// After popping, check for an indentation error.
 		// This occurs if currentIndent doesn't match any level that was on the stack.
 		if currentIndent != t.indentTop() {
-			t.tokenBufferPush(Token{
-				Type:   TokenError,
-				Value:  fmt.Sprintf("IndentationError: inconsistent dedent from level %d to %d", previousIndent, currentIndent),
-				Line:   t.line,
-				Column: t.column,
-			})
+			t.tokenBufferPush(newToken(TokenError,
+				fmt.Sprintf("IndentationError: inconsistent dedent from level %d to %d", previousIndent, currentIndent),
+				t.prev, t.prev))
 		}
 	}
+	return nil
+}
+
+// checkTabIndent reports tab used for indentation, since YAML allows
+// only spaces there. Tabs are fine as separation after indicators,
+// inside scalars, in blank lines, and before flow collection indicators,
+// which do not start a block node.
+// It must be called when the first content of a line, ch, is found.
+func (t *Tokenizer) checkTabIndent(ch rune) {
+	if t.lineBlank && t.leadingTab && !strings.ContainsRune("[]{}", ch) {
+		t.tokenBufferPush(newToken(TokenError,
+			fmt.Sprintf("TabIndentation: tab character used for indentation at column %d", t.leadingTabPos.column),
+			t.leadingTabPos, t.leadingTabPos.advance(1)))
+	}
+	t.lineBlank = false
 }
 
 func (t *Tokenizer) tokenBufferPush(token Token) {
+	t.tokenBufferPushResult(token, nil)
+}
+
+func (t *Tokenizer) tokenBufferPushResult(token Token, err error) {
 	if t.debug {
-		fmt.Printf("tokenBufferPush: %s at line %d, column %d\n",
-			token.String(), token.Line, token.Column)
+		t.logger.Debug("tokenBufferPush", "token", token.String(),
+			"line", token.Line, "column", token.Column, "err", err)
 	}
-	t.tokenBuffer = append(t.tokenBuffer, token)
+	t.tokenBuffer.push(bufferedToken{token: token, err: err})
+}
+
+func (t *Tokenizer) tokenBufferShift() (Token, error) {
+	b, ok := t.tokenBuffer.shift()
+	if !ok {
+		return t.returnError(errors.New("token buffer is empty"))
+	}
+	return b.result()
+}
+
+// here returns the position of the next rune.
+func (t *Tokenizer) here() position {
+	return position{line: t.line, column: t.column + 1, offset: t.offset}
 }
 
-func (t *Tokenizer) tokenBufferShift() Token {
-	// FIXME TODO XXX shifting slice is innefficient for FIFO, we should container/list
-	// FIXME TODO XXX for now we will crash if tokenBuffer is empty
-	var tk Token
-	tk, t.tokenBuffer = t.tokenBuffer[0], t.tokenBuffer[1:]
-	return tk
+// tokenFromStart creates a single-line token of n bytes starting at t.start.
+func (t *Tokenizer) tokenFromStart(tokenType TokenType, value string, n int) Token {
+	return newToken(tokenType, value, t.start, t.start.advance(n))
 }
 
 func (t *Tokenizer) returnError(err error) (Token, error) {
-	return Token{Type: TokenError, Line: t.line, Column: t.column}, err
+	return newToken(TokenError, "", t.here(), t.here()), err
 }
 
-func (t *Tokenizer) returnEOF() (Token, error) {
-	return Token{Type: TokenEOF, Line: t.line, Column: t.column}, io.EOF
+// lineBreakValue maps line breaks to escaped NEWLINE token values.
+var lineBreakValue = map[string]string{
+	"\n":   "\\n",
+	"\r\n": "\\r\\n",
+	"\r":   "\\r",
 }
 
 func (t *Tokenizer) returnNewLine() (Token, error) {
-	tk := Token{Type: TokenNewLine, Value: "\\n", Line: t.line, Column: t.column}
+	tk := newToken(TokenNewLine, lineBreakValue[t.lineBreak], t.prev, t.here())
 	t.line++
 	t.column = 0
+	t.lineBlank = true
+	t.leadingTab = false
+	t.lineIndent = 0
+	t.lineDash = false
 	return tk, nil
 }
 
 func (t *Tokenizer) returnDash() (Token, error) {
-	tk := Token{Type: TokenDash, Value: "-", Line: t.line, Column: t.column}
-	return tk, nil
+	t.lineDash = true
+	return t.tokenFromStart(TokenDash, "-", 1), nil
 }
 
 func (t *Tokenizer) returnDocStart() (Token, error) {
-	return Token{
-		Type:   TokenDocStart,
-		Value:  "---",
-		Line:   t.line,
-		Column: t.column - 3,
-	}, nil
+	return t.tokenFromStart(TokenDocStart, "---", 3), nil
 }
 
 func (t *Tokenizer) returnDocEnd() (Token, error) {
-	return Token{
-		Type:   TokenDocEnd,
-		Value:  "...",
-		Line:   t.line,
-		Column: t.column - 3,
-	}, nil
+	return t.tokenFromStart(TokenDocEnd, "...", 3), nil
 }
 
 func (t *Tokenizer) unreadAndReturnDash() (Token, error) {
@@ -164,130 +340,471 @@ func (t *Tokenizer) unreadAndReturnDash() (Token, error) {
 	return t.returnDash()
 }
 
+// nextRune reads the next rune, honoring a previous unreadRune.
+// Line breaks "\r\n" and "\r" are returned as a single '\n'.
+func (t *Tokenizer) nextRune() (rune, int, error) {
+	if t.unread {
+		t.unread = false
+		return t.lastRune, t.lastSize, nil
+	}
+	if t.failed {
+		return 0, 0, io.EOF
+	}
+	ch, size, err := t.reader.ReadRune()
+	if err != nil {
+		// sources keep returning an error, report it only once
+		t.failed = err != io.EOF
+		return ch, size, err
+	}
+	switch ch {
+	case '\n':
+		t.lineBreak = "\n"
+	case '\r':
+		t.lineBreak = "\r"
+		if peek, err := t.reader.peekByte(); err == nil && peek == '\n' {
+			if _, _, err := t.reader.ReadRune(); err != nil {
+				return 0, 0, err
+			}
+			t.lineBreak = "\r\n"
+			size++
+		}
+		ch = '\n'
+	}
+	if t.breakStyle == "" {
+		t.breakStyle = t.lineBreak
+	}
+	t.lastRune, t.lastSize = ch, size
+	return ch, size, nil
+}
+
 func (t *Tokenizer) readRune(caller string) (rune, error) {
-	ch, _, err := t.reader.ReadRune()
+	ch, size, err := t.nextRune()
 
 	if t.debug {
-		fmt.Printf("%s: %s: readRune: %d, err: %v\n",
-			caller, statusName[t.status], ch, err)
+		t.logger.Debug("readRune", "caller", caller,
+			"status", statusName[t.status], "rune", ch, "err", err)
 	}
 
 	if err != nil {
+		return 0, t.readError(err)
+	}
+
+	if ch == utf8.RuneError && size == 1 {
+		// the invalid byte was consumed from the source: move past it,
+		// so that the following tokens keep their positions
+		err = t.readError(fmt.Errorf("%w: invalid UTF-8 byte", ErrInvalidEncoding))
+		t.consume(size)
+		t.lineBlank = false
 		return 0, err
 	}
-	t.column++
+
+	t.consume(size)
 	return ch, nil
 }
 
+// consume moves the position past a rune of size bytes.
+func (t *Tokenizer) consume(size int) {
+	t.prev = t.here()
+	t.column++
+	t.offset += size
+}
+
+// readError adds the position to encoding errors.
+func (t *Tokenizer) readError(err error) error {
+	if errors.Is(err, ErrInvalidEncoding) {
+		return fmt.Errorf("line %d, column %d: %w", t.line, t.column+1, err)
+	}
+	return err
+}
+
 func (t *Tokenizer) unreadRune() error {
-	if err := t.reader.UnreadRune(); err != nil {
-		return err
+	if t.unread {
+		return errors.New("cannot unread more than one rune")
 	}
-	t.column--
+	t.unread = true
+	t.column = t.prev.column - 1
+	t.offset = t.prev.offset
 	return nil
 }
 
-func (t *Tokenizer) collectPlainScalar(scalar []rune) (Token, error) {
+// peekByte returns the first byte of the next rune without consuming it.
+// Line breaks are returned as '\n' or '\r'.
+//
+// A peek error ends the token being scanned, as end of input does. The
+// error is reported by the next read.
+func (t *Tokenizer) peekByte() (byte, error) {
+	if t.unread {
+		var buf [utf8.UTFMax]byte
+		utf8.EncodeRune(buf[:], t.lastRune)
+		return buf[0], nil
+	}
+	if t.failed {
+		return 0, io.EOF
+	}
+	return t.reader.peekByte()
+}
 
-	const me = "collectPlainScalar"
+// peekBlank checks whether the next rune is blank, line break or EOF.
+func (t *Tokenizer) peekBlank() bool {
+	peek, err := t.peekByte()
+	if err != nil {
+		return true // end of input
+	}
+	switch peek {
+	case ' ', '\t', '\n', '\r':
+		return true
+	}
+	return false
+}
+
+// pushPlainScalar collects a plain scalar starting at start, and pushes
+// it followed by the NEWLINE tokens consumed while looking for
+// continuation lines. The prefix was already consumed from start.
+func (t *Tokenizer) pushPlainScalar(start position, prefix string) {
+	t.status = statusBlank
+	t.tokenBufferPushResult(t.collectPlainScalar(start, prefix))
+	for _, tk := range t.pendingBreaks {
+		t.tokenBufferPush(tk)
+	}
+	t.pendingBreaks = t.pendingBreaks[:0]
+	if t.scalarErr != nil {
+		t.tokenBufferPushResult(t.returnError(t.scalarErr))
+		t.scalarErr = nil
+	}
+}
+
+// collectPlainScalar collects a plain scalar starting at start.
+// The prefix was already consumed from start.
+//
+// The scalar continues on following lines more indented than the current
+// block, folding each line break into a space, or into one '\n' for
+// each empty line. A top level scalar, outside of any block, continues on
+// lines at any indentation.
+//
+// A scalar longer than Options.MaxScalarLength is still consumed up to
+// its end, and is returned as an error token spanning it.
+func (t *Tokenizer) collectPlainScalar(start position, prefix string) (Token, error) {
+
+	t.scalarRoot = !t.lineDash && len(t.indentationLevelStack) == 1
+
+	// with a slicing source the text is copied only if the scalar folds
+	t.copying = !t.slicing
+	t.scratch = t.scratch[:0]
+	if t.copying {
+		t.scratch = append(t.scratch, prefix...)
+	}
+	t.scalarStart = start
+	t.scalarLimitErr = nil
+	t.scalarBytes = len(prefix)
+	t.scalarBlank = prefix != "" && isBlank(prefix[len(prefix)-1])
+
+	// trailing blanks of prefix are not content
+	t.contentEnd = start
+	if trimmed := len(strings.TrimRight(prefix, " \t")); trimmed > 0 {
+		blanks := len(prefix) - trimmed
+		here := t.here()
+		t.contentEnd = position{line: here.line, column: here.column - blanks, offset: here.offset - blanks}
+	}
 
+	var end position
 	for {
-		peek, err := t.reader.Peek(1)
-		if err == io.EOF {
+		err := t.collectPlainLine()
+		if errors.Is(err, ErrInvalidEncoding) {
+			// keep the text read so far, the error follows the scalar
+			t.scalarErr = err
+			end = t.contentEnd
 			break
 		}
 		if err != nil {
 			return t.returnError(err)
 		}
+		end = t.contentEnd // exclude trailing blanks
+		if end.offset == start.offset {
+			break // empty scalar does not continue
+		}
+		fold, err := t.foldPlainLines()
+		if err != nil {
+			return t.returnError(err)
+		}
+		if !fold {
+			break
+		}
+	}
+
+	if t.scalarLimitErr != nil {
+		return newToken(TokenError, "", start, end), t.scalarLimitErr
+	}
+
+	if t.copying {
+		t.scratch = bytes.TrimRight(t.scratch, " \t")
+		return newToken(TokenPlainScalar, string(t.scratch), start, end), nil
+	}
+	raw, _ := t.reader.slice(start.offset, end.offset)
+	return newToken(TokenPlainScalar, string(raw), start, end), nil
+}
+
+// collectPlainLine reads the scalar text up to the end of current line,
+// or up to a comment, appending it to t.scratch when copying. A comment
+// starts at '#' preceded by a blank, since a plain scalar cannot start
+// with '#'. It records in t.contentEnd the position past the last
+// non-blank rune.
+func (t *Tokenizer) collectPlainLine() error {
 
-		if peek[0] == '\n' || peek[0] == '#' {
+	const me = "collectPlainLine"
+
+	for {
+		peek, err := t.peekByte()
+		if err != nil {
+			return nil // end of input
+		}
+
+		if peek == '\n' || peek == '\r' {
+			return nil
+		}
+		if peek == '#' && (t.scalarBytes == 0 || t.scalarBlank) {
+			return nil
+		}
+		ch, err := t.readRune(me)
+		if err != nil {
+			return err
+		}
+		t.scalarBlank = ch == ' ' || ch == '\t'
+		if !t.scalarBlank {
+			t.contentEnd = t.here()
+		}
+		t.scalarBytes += t.lastSize
+		if t.scalarLimitErr != nil {
+			continue // skipping the rest of the scalar
+		}
+		if t.copying {
+			t.scratch = utf8.AppendRune(t.scratch, ch)
+		}
+		size := t.scalarBytes
+		if t.copying {
+			size = len(t.scratch)
+		}
+		if limit := t.options.MaxScalarLength; limit > 0 && size > limit {
+			t.scalarLimitErr = fmt.Errorf("scalar exceeds length limit of %d bytes", limit)
+		}
+	}
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// collectComment collects a comment starting at start, up to the end of
+// current line. The '#' was already consumed from start.
+// The comment is pushed only if Options.KeepComments is set.
+func (t *Tokenizer) collectComment(start position) error {
+
+	const me = "collectComment"
+
+	t.scratch = append(t.scratch[:0], '#')
+
+	var encodingErr error
+	for {
+		peek, err := t.peekByte()
+		if err != nil {
+			break // end of input
+		}
+		if peek == '\n' || peek == '\r' {
 			break
 		}
 		ch, err := t.readRune(me)
+		if errors.Is(err, ErrInvalidEncoding) {
+			// drop the comment, but skip it up to the end of line
+			if encodingErr == nil {
+				encodingErr = err
+			}
+			continue
+		}
 		if err != nil {
-			return t.returnError(err)
+			return err
+		}
+		if t.options.KeepComments && !t.slicing {
+			t.scratch = utf8.AppendRune(t.scratch, ch)
 		}
-		scalar = append(scalar, ch)
 	}
 
-	value := string(scalar)
+	if encodingErr != nil || !t.options.KeepComments {
+		return encodingErr
+	}
 
-	return Token{
-		Type:   TokenPlainScalar,
-		Value:  value,
-		Line:   t.line,
-		Column: t.column - len(value),
-	}, nil
+	end := t.here()
+	raw, ok := t.reader.slice(start.offset, end.offset)
+	if !ok {
+		raw = t.scratch
+	}
+	t.tokenBufferPush(newToken(TokenComment, string(raw), start, end))
+	return nil
 }
 
-func (t *Tokenizer) pushPerStateEOF() {
+// foldPlainLines consumes line breaks, empty lines and the indentation
+// of the next line. If that line continues the scalar, it folds the line
+// breaks into t.scratch and returns true. Otherwise the consumed line
+// breaks are left in t.pendingBreaks.
+func (t *Tokenizer) foldPlainLines() (bool, error) {
+
+	const me = "foldPlainLines"
+
+	var breaks int
+	for {
+		peek, err := t.peekByte()
+		if err != nil {
+			return false, nil // end of input
+		}
+
+		switch peek {
+		case '\n', '\r':
+			if _, err := t.readRune(me); err != nil {
+				return false, err
+			}
+			tk, _ := t.returnNewLine()
+			t.pendingBreaks = append(t.pendingBreaks, tk)
+			breaks++
+			continue
+		case ' ':
+			if !t.leadingTab {
+				t.lineIndent++
+			}
+		case '\t':
+			if !t.leadingTab {
+				t.leadingTab = true
+				t.leadingTabPos = t.here()
+			}
+		default:
+			minIndent := t.indentTop() + 1
+			if t.scalarRoot {
+				minIndent = 0
+			}
+			if breaks == 0 || t.lineIndent < minIndent || peek == '#' ||
+				(t.scalarRoot && t.atRootIndicator()) {
+				return false, nil
+			}
+
+			// continuation line
+			t.pendingBreaks = t.pendingBreaks[:0]
+			t.lineBlank = false
+			t.leadingTab = false
+			if t.scalarLimitErr != nil {
+				return true, nil // skipping the rest of the scalar
+			}
+			if !t.copying {
+				// first fold: copy the text so far out of the source
+				raw, _ := t.reader.slice(t.scalarStart.offset, t.contentEnd.offset)
+				t.scratch = append(t.scratch[:0], raw...)
+				t.copying = true
+			}
+			t.scratch = bytes.TrimRight(t.scratch, " \t")
+			if breaks == 1 {
+				t.scratch = append(t.scratch, ' ')
+			}
+			for range breaks - 1 {
+				t.scratch = append(t.scratch, '\n')
+			}
+			return true, nil
+		}
+
+		if _, err := t.readRune(me); err != nil {
+			return false, err
+		}
+	}
+}
+
+// pushPerState pushes the token pending in a multi-rune status.
+func (t *Tokenizer) pushPerState() {
 
 	switch t.status {
 	case statusOneDash:
-		t.tokenBufferPush(Token{
-			Type:   TokenDash,
-			Value:  "-",
-			Line:   t.line,
-			Column: t.column - 1,
-		})
+		t.tokenBufferPush(t.tokenFromStart(TokenDash, "-", 1))
 	case statusTwoDashes:
-		t.tokenBufferPush(Token{
-			Type:   TokenPlainScalar,
-			Value:  "--",
-			Line:   t.line,
-			Column: t.column - 2,
-		})
+		t.tokenBufferPush(t.tokenFromStart(TokenPlainScalar, "--", 2))
 	case statusThreeDashes:
-		t.tokenBufferPush(Token{
-			Type:   TokenDocStart,
-			Value:  "---",
-			Line:   t.line,
-			Column: t.column - 3,
-		})
+		t.tokenBufferPush(t.tokenFromStart(TokenDocStart, "---", 3))
 	case statusOneDot:
-		t.tokenBufferPush(Token{
-			Type:   TokenPlainScalar,
-			Value:  ".",
-			Line:   t.line,
-			Column: t.column - 1,
-		})
+		t.tokenBufferPush(t.tokenFromStart(TokenPlainScalar, ".", 1))
 	case statusTwoDots:
-		t.tokenBufferPush(Token{
-			Type:   TokenPlainScalar,
-			Value:  "..",
-			Line:   t.line,
-			Column: t.column - 2,
-		})
+		t.tokenBufferPush(t.tokenFromStart(TokenPlainScalar, "..", 2))
 	case statusThreeDots:
-		t.tokenBufferPush(Token{
-			Type:   TokenDocEnd,
-			Value:  "...",
-			Line:   t.line,
-			Column: t.column - 3,
-		})
+		t.tokenBufferPush(t.tokenFromStart(TokenDocEnd, "...", 3))
 	}
+	t.status = statusBlank
+}
+
+// atRootIndicator checks whether the next content, at top level, is a
+// sequence entry or a document marker instead of a scalar continuation
+// line. A document marker must start at column 1.
+func (t *Tokenizer) atRootIndicator() bool {
+	next := t.reader.peekBytes(4)
+	blankAt := func(i int) bool {
+		return i >= len(next) || isBlank(next[i]) || next[i] == '\n' || next[i] == '\r'
+	}
+	if len(next) > 0 && next[0] == '-' && blankAt(1) {
+		return true
+	}
+	if t.column > 0 {
+		return false
+	}
+	return (bytes.HasPrefix(next, []byte("---")) || bytes.HasPrefix(next, []byte("..."))) && blankAt(3)
+}
+
+func (t *Tokenizer) pushPerStateEOF() {
+
+	t.pushPerState()
 
 	for len(t.indentationLevelStack) > 1 {
 		t.indentPop()
-		t.tokenBufferPush(Token{Type: TokenDedent, Line: t.line, Column: t.column})
+		t.tokenBufferPush(newToken(TokenDedent, "", t.here(), t.here()))
 
 	}
 
-	t.tokenBufferPush(Token{Type: TokenEOF, Line: t.line, Column: t.column})
+	t.tokenBufferPush(newToken(TokenEOF, "", t.here(), t.here()))
 }
 
 // NextToken gets next token.
+//
+// Scanning goes on after an error token, so the stream always ends with
+// TokenEOF. An error from the input source, such as malformed UTF-16,
+// ends the input: TokenEOF follows it.
 func (t *Tokenizer) NextToken() (Token, error) {
-	const me = "NextToken"
+	if t.tokenBuffer.len() == 0 {
+		t.scan()
+	}
+	return t.tokenBufferShift()
+}
+
+// Peek returns the next token without consuming it.
+func (t *Tokenizer) Peek() (Token, error) {
+	return t.PeekN(1)
+}
+
+// PeekN returns the n-th next token without consuming it.
+// PeekN(1) is the same as Peek().
+// Peeking past EOF or an error returns that EOF or error.
+func (t *Tokenizer) PeekN(n int) (Token, error) {
+	if n < 1 {
+		return t.returnError(fmt.Errorf("invalid peek distance: %d", n))
+	}
+	for t.tokenBuffer.len() < n {
+		if size := t.tokenBuffer.len(); size > 0 {
+			if last := t.tokenBuffer.at(size - 1); last.token.Type == TokenEOF || last.err != nil {
+				return last.result()
+			}
+		}
+		t.scan()
+	}
+	return t.tokenBuffer.at(n - 1).result()
+}
+
+// scan reads runes until at least one token is pushed into the buffer.
+func (t *Tokenizer) scan() {
+	const me = "scan"
+	mark := t.tokenBuffer.len()
 NEXT_RUNE:
 	for {
-		if len(t.tokenBuffer) > 0 {
-			// first return buffered token
-			tk := t.tokenBufferShift()
-			if tk.Type == TokenEOF {
-				return tk, io.EOF
-			}
-			return tk, nil
+		if t.tokenBuffer.len() > mark {
+			return
 		}
 
 		ch, err := t.readRune(me)
@@ -297,7 +814,10 @@ NEXT_RUNE:
 			continue
 		}
 		if err != nil {
-			return t.returnError(err)
+			// flush the pending token and scan again from a blank status
+			t.pushPerState()
+			t.tokenBufferPushResult(t.returnError(err))
+			return
 		}
 
 		switch t.status {
@@ -305,27 +825,70 @@ NEXT_RUNE:
 		case statusBlank:
 			switch ch {
 			case ' ':
+				if t.lineBlank && !t.leadingTab {
+					t.lineIndent++
+				}
+				continue NEXT_RUNE
+			case '\t':
+				if t.lineBlank && !t.leadingTab {
+					// tab is fine in blank lines, report only if content follows
+					t.leadingTab = true
+					t.leadingTabPos = t.prev
+				}
 				continue NEXT_RUNE
 			case '\n':
-				return t.returnNewLine()
+				t.tokenBufferPushResult(t.returnNewLine())
+				return
+			case '#':
+				// blank or start of line precedes, hence a comment
+				if err := t.collectComment(t.prev); err != nil {
+					t.tokenBufferPushResult(t.returnError(err))
+					return
+				}
+				continue NEXT_RUNE
 			case '-':
 				// Only match dash if it's at the beginning of a line
 				if t.column == 1 {
+					if err := t.startContent(ch); err != nil {
+						t.tokenBufferPushResult(t.returnError(err))
+						return
+					}
+					t.start = t.prev
+					t.status = statusOneDash
+					continue NEXT_RUNE
+				}
+				// Indented dash followed by blank, line break or end of
+				// input is a sequence entry, as at column 1, so that
+				// nested sequences tokenize as INDENT DASH. Otherwise,
+				// as in " -a", the dash starts a plain scalar.
+				if t.peekBlank() {
+					if err := t.startContent(ch); err != nil {
+						t.tokenBufferPushResult(t.returnError(err))
+						return
+					}
+					t.start = t.prev
 					t.status = statusOneDash
 					continue NEXT_RUNE
 				}
 			case '.':
 				if t.column == 1 {
+					if err := t.startContent(ch); err != nil {
+						t.tokenBufferPushResult(t.returnError(err))
+						return
+					}
+					t.start = t.prev
 					t.status = statusOneDot
 					continue NEXT_RUNE
 				}
 			}
 
-			t.checkIndent()
+			if err := t.startContent(ch); err != nil {
+				t.tokenBufferPushResult(t.returnError(err))
+				return
+			}
 
-			tk, _ := t.collectPlainScalar([]rune{ch})
-			t.tokenBufferPush(tk)
-			continue
+			t.pushPlainScalar(t.prev, string(ch))
+			return
 
 		case statusOneDot:
 			switch ch {
@@ -335,17 +898,14 @@ NEXT_RUNE:
 			case '\n':
 				t.status = statusBlank
 				if err := t.unreadRune(); err != nil {
-					return t.returnError(err)
+					t.tokenBufferPushResult(t.returnError(err))
+					return
 				}
-				return Token{
-					Type:   TokenPlainScalar,
-					Value:  ".",
-					Line:   t.line,
-					Column: t.column - 1,
-				}, nil
+				t.tokenBufferPushResult(t.tokenFromStart(TokenPlainScalar, ".", 1), nil)
+				return
 			}
-			t.status = statusScalar
-			return t.collectPlainScalar([]rune{'.', ch})
+			t.pushPlainScalar(t.start, "."+string(ch))
+			return
 
 		case statusTwoDots:
 			switch ch {
@@ -355,129 +915,105 @@ NEXT_RUNE:
 			case '\n':
 				t.status = statusBlank
 				if err := t.unreadRune(); err != nil {
-					return t.returnError(err)
+					t.tokenBufferPushResult(t.returnError(err))
+					return
 				}
-				return Token{
-					Type:   TokenPlainScalar,
-					Value:  "..",
-					Line:   t.line,
-					Column: t.column - 2,
-				}, nil
+				t.tokenBufferPushResult(t.tokenFromStart(TokenPlainScalar, "..", 2), nil)
+				return
 			}
-			t.status = statusScalar
-			return t.collectPlainScalar([]rune{'.', '.', ch})
+			t.pushPlainScalar(t.start, ".."+string(ch))
+			return
 
 		case statusThreeDots:
 			switch ch {
-			case ' ':
+			case ' ', '\t':
 				t.status = statusScalar
-				return t.returnDocEnd()
+				t.tokenBufferPushResult(t.returnDocEnd())
+				return
 			case '\n':
 				t.status = statusBlank
 				if err := t.unreadRune(); err != nil {
-					return t.returnError(err)
+					t.tokenBufferPushResult(t.returnError(err))
+					return
 				}
-				return t.returnDocEnd()
+				t.tokenBufferPushResult(t.returnDocEnd())
+				return
 			}
-			t.status = statusScalar
-			return t.collectPlainScalar([]rune{'.', '.', '.', ch})
+			t.pushPlainScalar(t.start, "..."+string(ch))
+			return
 
 		case statusOneDash:
 			switch ch {
-			case ' ':
+			case ' ', '\t':
 				t.status = statusScalar
-				return t.returnDash()
-			case '\t':
-				t.status = statusAfterDash
-				return t.unreadAndReturnDash()
+				t.tokenBufferPushResult(t.returnDash())
+				return
 			case '\n':
 				t.status = statusBlank
-				return t.unreadAndReturnDash()
+				t.tokenBufferPushResult(t.unreadAndReturnDash())
+				return
 			case '-':
 				t.status = statusTwoDashes
 				continue NEXT_RUNE
 			}
-			t.status = statusBlank
-			return t.collectPlainScalar([]rune{'-', ch})
+			t.pushPlainScalar(t.start, "-"+string(ch))
+			return
 
 		case statusTwoDashes:
 			switch ch {
-			case ' ':
-				t.status = statusBlank
-				return Token{
-					Type:   TokenPlainScalar,
-					Value:  "--",
-					Line:   t.line,
-					Column: t.column - 2,
-				}, nil
 			case '\n':
 				t.status = statusBlank
 				if err := t.unreadRune(); err != nil {
-					return t.returnError(err)
+					t.tokenBufferPushResult(t.returnError(err))
+					return
 				}
-				return Token{
-					Type:   TokenPlainScalar,
-					Value:  "--",
-					Line:   t.line,
-					Column: t.column - 2,
-				}, nil
+				t.tokenBufferPushResult(t.tokenFromStart(TokenPlainScalar, "--", 2), nil)
+				return
 			case '-':
 				t.status = statusThreeDashes
 				continue NEXT_RUNE
 			}
-			t.status = statusBlank
-			return t.collectPlainScalar([]rune{'-', '-', ch})
+			t.pushPlainScalar(t.start, "--"+string(ch))
+			return
 
 		case statusThreeDashes:
 			switch ch {
-			case ' ':
+			case ' ', '\t':
 				t.status = statusScalar
-				return t.returnDocStart()
+				t.tokenBufferPushResult(t.returnDocStart())
+				return
 			case '\n':
 				t.status = statusBlank
 				if err := t.unreadRune(); err != nil {
-					return t.returnError(err)
+					t.tokenBufferPushResult(t.returnError(err))
+					return
 				}
-				return t.returnDocStart()
+				t.tokenBufferPushResult(t.returnDocStart())
+				return
 			}
-			t.status = statusBlank
-			return t.collectPlainScalar([]rune{'-', '-', '-', ch})
-
-		case statusAfterDash:
-			t.status = statusBlank
-			var scalar []rune
-			// skip blanks
-			for {
-				if ch == '\n' {
-					return t.returnNewLine()
-				}
-				if ch != ' ' {
-					scalar = append(scalar, ch)
-					break
-				}
-
-				ch, err = t.readRune(fmt.Sprintf("%s: statusAfterDash", me))
-				if err == io.EOF {
-					return t.returnEOF()
-				}
-				if err != nil {
-					return t.returnError(err)
-				}
-			}
-			return t.collectPlainScalar(scalar)
+			t.pushPlainScalar(t.start, "---"+string(ch))
+			return
 
 		case statusScalar:
+			if ch == ' ' || ch == '\t' {
+				// skip separation blanks
+				continue NEXT_RUNE
+			}
 			t.status = statusBlank
-			if ch == '\n' {
+			if ch == '\n' || ch == '#' {
 				if err := t.unreadRune(); err != nil {
-					return t.returnError(err)
+					t.tokenBufferPushResult(t.returnError(err))
+					return
 				}
-				return t.collectPlainScalar(nil)
+				t.pushPlainScalar(t.here(), "")
+				return
 			}
-			return t.collectPlainScalar([]rune{ch})
+			t.pushPlainScalar(t.prev, string(ch))
+			return
 
 		default:
-			return t.returnError(fmt.Errorf("unexpected token status: %d", t.status))
+			t.tokenBufferPushResult(t.returnError(fmt.Errorf("unexpected token status: %d", t.status)))
+			return
 		}
 
 	}
@@ -497,6 +1033,7 @@ const (
 	TokenDocEnd   // for '...'
 	TokenIndent
 	TokenDedent
+	TokenComment
 )
 
 var tokenTypeName = []string{
@@ -509,6 +1046,15 @@ var tokenTypeName = []string{
 	"DOC-END",
 	"INDENT",
 	"DEDENT",
+	"COMMENT",
+}
+
+// String returns the token type name, such as "PLAIN-SCALAR".
+func (tt TokenType) String() string {
+	if tt < 0 || int(tt) >= len(tokenTypeName) {
+		return fmt.Sprintf("TokenType(%d)", int(tt))
+	}
+	return tokenTypeName[tt]
 }
 
 // TokenEqual checks two tokens for equality.
@@ -517,23 +1063,52 @@ func TokenEqual(t1, t2 Token) bool {
 		return false
 	}
 	switch t1.Type {
-	case TokenPlainScalar:
+	case TokenPlainScalar, TokenComment:
 		return t1.Value == t2.Value
 	}
 	return true
 }
 
 // Token defines yaml token.
+//
+// Line and Column locate the first character of the token.
+// EndLine and EndColumn locate the position just past its last character.
+// Lines and columns are 1-based, and columns count runes, not bytes.
+//
+// Offset and EndOffset are 0-based byte offsets into the input,
+// such that input[Offset:EndOffset] is the raw token text.
 type Token struct {
-	Type   TokenType
-	Value  string
-	Line   int
-	Column int
+	Type      TokenType
+	Value     string
+	Line      int
+	Column    int
+	Offset    int
+	EndLine   int
+	EndColumn int
+	EndOffset int
+}
+
+func newToken(tokenType TokenType, value string, start, end position) Token {
+	return Token{
+		Type:      tokenType,
+		Value:     value,
+		Line:      start.line,
+		Column:    start.column,
+		Offset:    start.offset,
+		EndLine:   end.line,
+		EndColumn: end.column,
+		EndOffset: end.offset,
+	}
 }
 
 func (t *Token) String() string {
-	if t.Type == TokenPlainScalar {
-		return fmt.Sprintf("%s(%s)", tokenTypeName[t.Type], t.Value)
+	switch t.Type {
+	case TokenPlainScalar, TokenComment:
+		return fmt.Sprintf("%s(%s)", t.Type, t.Value)
+	case TokenError:
+		if t.Value != "" {
+			return fmt.Sprintf("%s(%s)", t.Type, t.Value)
+		}
 	}
-	return fmt.Sprintf("%s", tokenTypeName[t.Type])
+	return t.Type.String()
 }
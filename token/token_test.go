@@ -135,7 +135,7 @@ var tokenizerTestTable = []tokenizerTest{
 	}},
 	{"doc-start-with-scalar-two-spaces", "---  hello\n", []Token{
 		{Type: TokenDocStart, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: " hello", Line: 1, Column: 5},
+		{Type: TokenPlainScalar, Value: "hello", Line: 1, Column: 6},
 		{Type: TokenNewLine, Line: 1, Column: 10},
 	}},
 	{"false-doc-start-four-dashes", "----", []Token{
@@ -151,12 +151,12 @@ var tokenizerTestTable = []tokenizerTest{
 	}},
 	{"scalar-with-tab", "-\tvalue\n", []Token{
 		{Type: TokenDash, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: "\tvalue", Line: 1, Column: 3},
+		{Type: TokenPlainScalar, Value: "value", Line: 1, Column: 3},
 		{Type: TokenNewLine, Line: 1, Column: 9},
 	}},
 	{"empty-scalar-two-spaces", "-  \n", []Token{
 		{Type: TokenDash, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: " ", Line: 1, Column: 4},
+		{Type: TokenPlainScalar, Value: "", Line: 1, Column: 4},
 		{Type: TokenNewLine, Line: 1, Column: 4},
 	}},
 	{"empty-scalar-after-dash", "- \n", []Token{
@@ -166,21 +166,21 @@ var tokenizerTestTable = []tokenizerTest{
 	}},
 	{"plain-scalar-with-spaces", "-  hello world  \n", []Token{
 		{Type: TokenDash, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: " hello world  ", Line: 1, Column: 3},
+		{Type: TokenPlainScalar, Value: "hello world", Line: 1, Column: 4},
 		{Type: TokenNewLine, Line: 1, Column: 17},
 	}},
 	{"plain-scalar-with-spaces-no-newline", "-  hello world  ", []Token{
 		{Type: TokenDash, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: " hello world  ", Line: 1, Column: 3},
+		{Type: TokenPlainScalar, Value: "hello world", Line: 1, Column: 4},
 	}},
 	{"tab-only-scalar", "-\t\t\n", []Token{
 		{Type: TokenDash, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: "\t\t", Line: 1, Column: 3},
+		{Type: TokenPlainScalar, Value: "", Line: 1, Column: 4},
 		{Type: TokenNewLine, Line: 1, Column: 4},
 	}},
 	{"scalar-tabs-and-spaces", "- \t foo\t \n", []Token{
 		{Type: TokenDash, Line: 1, Column: 1},
-		{Type: TokenPlainScalar, Value: "\t foo\t ", Line: 1, Column: 3},
+		{Type: TokenPlainScalar, Value: "foo", Line: 1, Column: 5},
 		{Type: TokenNewLine, Line: 1, Column: 10},
 	}},
 	{"multiple-newlines-eof", "\n\n\n", []Token{
@@ -221,7 +221,8 @@ var tokenizerTestTable = []tokenizerTest{
 		{Type: TokenDedent, Line: 1, Column: 5},
 	}},
 	{"false-doc-end-tab-indented", "\t...", []Token{
-		{Type: TokenPlainScalar, Value: "\t...", Line: 1, Column: 2},
+		{Type: TokenError, Line: 1, Column: 1},
+		{Type: TokenPlainScalar, Value: "...", Line: 1, Column: 2},
 	}},
 	{"false-doc-end-inline", "...value", []Token{
 		{Type: TokenPlainScalar, Value: "...value", Line: 1, Column: 1},
@@ -253,11 +254,7 @@ func TestTokenizer(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			tokenizer := NewTokenizer(strings.NewReader(data.input), debug)
 			var tokens []Token
-			for {
-				tk, err := tokenizer.NextToken()
-				if err == io.EOF && tk.Type == TokenEOF {
-					break
-				}
+			for tk, err := range tokenizer.All() {
 				if err != nil {
 					t.Error(err)
 					return
@@ -281,3 +278,63 @@ func formatTokens(list []Token) string {
 	}
 	return strings.Join(result, ",")
 }
+
+// go test -count 1 -run '^TestTokenPositions$' ./...
+func TestTokenPositions(t *testing.T) {
+
+	table := append(slices.Clone(tokenizerTestTable), indentTestTable...)
+	table = append(table, tokenizerTest{name: "non-ascii", input: "- ação\n- 日本\n"})
+
+	for i, data := range table {
+		name := fmt.Sprintf("%02d of %02d: %s", i+1, len(table), data.name)
+
+		t.Run(name, func(t *testing.T) {
+			tokens, err := Tokenize([]byte(data.input))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for _, tk := range tokens {
+				raw := data.input[tk.Offset:tk.EndOffset]
+				var expected string
+				switch tk.Type {
+				case TokenPlainScalar, TokenDash, TokenDocStart, TokenDocEnd:
+					expected = tk.Value
+				case TokenError:
+					expected = raw // error spans point at the offending input
+				case TokenNewLine:
+					expected = strings.NewReplacer(`\n`, "\n", `\r`, "\r").Replace(tk.Value)
+				}
+				if raw != expected {
+					t.Errorf("token %s: raw text %q, expected %q", tk.String(), raw, expected)
+				}
+				line, column := lineColumn(data.input, tk.Offset)
+				if line != tk.Line || column != tk.Column {
+					t.Errorf("token %s: start %d:%d, expected %d:%d", tk.String(), tk.Line, tk.Column, line, column)
+				}
+				line, column = lineColumn(data.input, tk.EndOffset)
+				if tk.Type == TokenNewLine {
+					// newline ends past its last character on the same line
+					line, column = tk.Line, tk.Column+1
+				}
+				if line != tk.EndLine || column != tk.EndColumn {
+					t.Errorf("token %s: end %d:%d, expected %d:%d", tk.String(), tk.EndLine, tk.EndColumn, line, column)
+				}
+			}
+		})
+	}
+}
+
+// lineColumn finds rune-based line and column for byte offset.
+func lineColumn(input string, offset int) (int, int) {
+	line, column := 1, 1
+	for _, ch := range input[:offset] {
+		if ch == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return line, column
+}
@@ -0,0 +1,364 @@
+// Package yamlot holds the YAML data model shared by the decoder and
+// the encoder. The tokenizer lives in package token.
+package yamlot
+
+import (
+	"iter"
+	"math"
+	"strconv"
+)
+
+// Kind identifies the type of a Value.
+type Kind int
+
+// Value kinds. The zero Value is Invalid: it is what accessors return for
+// a missing node.
+const (
+	KindInvalid Kind = iota
+	KindNull
+	KindBool
+	KindInt
+	KindFloat
+	KindString
+	KindSequence
+	KindMapping
+)
+
+var kindName = []string{
+	"invalid",
+	"null",
+	"bool",
+	"int",
+	"float",
+	"string",
+	"sequence",
+	"mapping",
+}
+
+func (k Kind) String() string {
+	return kindName[k]
+}
+
+// Value is a dynamic YAML node, like an any holding decoded data, except
+// that mappings keep key insertion order and accept keys of any kind,
+// including sequences and mappings.
+//
+// Sequences and mappings are shared between copies of a Value.
+type Value struct {
+	kind    Kind
+	scalar  any // bool, int64, float64 or string
+	items   []Value
+	mapping *Mapping
+}
+
+// Pair is a mapping entry.
+type Pair struct {
+	Key   Value
+	Value Value
+}
+
+// NewNull returns a null value.
+func NewNull() Value {
+	return Value{kind: KindNull}
+}
+
+// NewBool returns a bool value.
+func NewBool(b bool) Value {
+	return Value{kind: KindBool, scalar: b}
+}
+
+// NewInt returns an int value.
+func NewInt(i int64) Value {
+	return Value{kind: KindInt, scalar: i}
+}
+
+// NewFloat returns a float value.
+func NewFloat(f float64) Value {
+	return Value{kind: KindFloat, scalar: f}
+}
+
+// NewString returns a string value.
+func NewString(s string) Value {
+	return Value{kind: KindString, scalar: s}
+}
+
+// NewSequence returns a sequence of items. The items are not copied.
+func NewSequence(items ...Value) Value {
+	return Value{kind: KindSequence, items: items}
+}
+
+// NewMapping returns a mapping of pairs, in order. A repeated key
+// replaces the value of its first occurrence, as in Mapping.Set.
+func NewMapping(pairs ...Pair) Value {
+	m := &Mapping{}
+	for _, p := range pairs {
+		m.Set(p.Key, p.Value)
+	}
+	return Value{kind: KindMapping, mapping: m}
+}
+
+// Kind returns the kind of v.
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// IsValid reports whether v holds a node. Accessors return an invalid
+// Value for missing nodes.
+func (v Value) IsValid() bool {
+	return v.kind != KindInvalid
+}
+
+// IsNull reports whether v is null.
+func (v Value) IsNull() bool {
+	return v.kind == KindNull
+}
+
+// Bool returns the bool held by v, or false for other kinds.
+func (v Value) Bool() bool {
+	b, _ := v.scalar.(bool)
+	return b
+}
+
+// Int returns the int held by v, or zero for other kinds.
+func (v Value) Int() int64 {
+	i, _ := v.scalar.(int64)
+	return i
+}
+
+// Float returns the float held by v, converting an int, or zero for
+// other kinds.
+func (v Value) Float() float64 {
+	switch s := v.scalar.(type) {
+	case float64:
+		return s
+	case int64:
+		return float64(s)
+	}
+	return 0
+}
+
+// String returns the text of a string value, and the canonical YAML 1.2
+// core schema form of other scalars, such as "null", "true", "12" or
+// ".inf". It returns "" for sequences, mappings and invalid values.
+func (v Value) String() string {
+	switch v.kind {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return strconv.FormatBool(v.Bool())
+	case KindInt:
+		return strconv.FormatInt(v.Int(), 10)
+	case KindFloat:
+		return formatFloat(v.Float())
+	case KindString:
+		return v.scalar.(string)
+	}
+	return ""
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Len returns the number of items of a sequence or pairs of a mapping,
+// or zero for other kinds.
+func (v Value) Len() int {
+	switch v.kind {
+	case KindSequence:
+		return len(v.items)
+	case KindMapping:
+		return v.mapping.Len()
+	}
+	return 0
+}
+
+// Items returns the items of a sequence, or nil for other kinds.
+// The result shares memory with v.
+func (v Value) Items() []Value {
+	return v.items
+}
+
+// Mapping returns the mapping held by v, or nil for other kinds.
+func (v Value) Mapping() *Mapping {
+	return v.mapping
+}
+
+// Index returns item i of a sequence, or an invalid Value when v is not
+// a sequence or i is out of range.
+func (v Value) Index(i int) Value {
+	if i < 0 || i >= len(v.items) {
+		return Value{}
+	}
+	return v.items[i]
+}
+
+// Lookup returns the value of key in a mapping, or an invalid Value when
+// v is not a mapping or has no such key.
+func (v Value) Lookup(key Value) Value {
+	if v.mapping == nil {
+		return Value{}
+	}
+	value, _ := v.mapping.Get(key)
+	return value
+}
+
+// Get follows path from v and returns the node found, or an invalid
+// Value when some step is missing, so that calls can be chained as in
+// v.Get("a", 0, "b").String().
+//
+// A string step looks up a string key. An int step indexes a sequence,
+// or looks up an int key in a mapping. A Value step looks up that key,
+// for keys of other kinds. Steps of other types are missing.
+func (v Value) Get(path ...any) Value {
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			v = v.Lookup(NewString(s))
+		case int:
+			if v.kind == KindSequence {
+				v = v.Index(s)
+			} else {
+				v = v.Lookup(NewInt(int64(s)))
+			}
+		case Value:
+			v = v.Lookup(s)
+		default:
+			return Value{}
+		}
+		if !v.IsValid() {
+			break
+		}
+	}
+	return v
+}
+
+// Equal reports whether v and w are the same node. Sequences are equal
+// item by item, mappings pair by pair in order. An int is not equal to a
+// float of the same number, and a NaN float is not equal to itself.
+func (v Value) Equal(w Value) bool {
+	if v.kind != w.kind {
+		return false
+	}
+	switch v.kind {
+	case KindSequence:
+		if len(v.items) != len(w.items) {
+			return false
+		}
+		for i := range v.items {
+			if !v.items[i].Equal(w.items[i]) {
+				return false
+			}
+		}
+		return true
+	case KindMapping:
+		if v.mapping.Len() != w.mapping.Len() {
+			return false
+		}
+		for i, p := range v.mapping.pairs {
+			q := w.mapping.pairs[i]
+			if !p.Key.Equal(q.Key) || !p.Value.Equal(q.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.scalar == w.scalar
+}
+
+// Mapping is an ordered mapping. It keeps pairs in key insertion order,
+// and accepts keys of any kind. Scalar keys are found through an index,
+// sequence and mapping keys by comparing them in turn.
+//
+// The zero Mapping is empty and ready to use.
+type Mapping struct {
+	pairs []Pair
+	index map[scalarKey]int // position of scalar keys in pairs
+}
+
+// scalarKey is the comparable form of a scalar key.
+type scalarKey struct {
+	kind   Kind
+	scalar any
+}
+
+// Len returns the number of pairs in m.
+func (m *Mapping) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.pairs)
+}
+
+// Get returns the value of key, and whether key is found.
+func (m *Mapping) Get(key Value) (Value, bool) {
+	if i := m.find(key); i >= 0 {
+		return m.pairs[i].Value, true
+	}
+	return Value{}, false
+}
+
+// Set sets the value of key. A new key goes after the existing ones, an
+// existing key keeps its position.
+func (m *Mapping) Set(key, value Value) {
+	if i := m.find(key); i >= 0 {
+		m.pairs[i].Value = value
+		return
+	}
+	if isScalarKey(key) {
+		if m.index == nil {
+			m.index = map[scalarKey]int{}
+		}
+		m.index[scalarKey{key.kind, key.scalar}] = len(m.pairs)
+	}
+	m.pairs = append(m.pairs, Pair{Key: key, Value: value})
+}
+
+// Pairs returns the pairs of m in order. The result shares memory with m.
+func (m *Mapping) Pairs() []Pair {
+	if m == nil {
+		return nil
+	}
+	return m.pairs
+}
+
+// All returns an iterator over the keys and values of m, in order.
+func (m *Mapping) All() iter.Seq2[Value, Value] {
+	return func(yield func(Value, Value) bool) {
+		for _, p := range m.Pairs() {
+			if !yield(p.Key, p.Value) {
+				return
+			}
+		}
+	}
+}
+
+// find returns the position of key in m.pairs, or -1.
+func (m *Mapping) find(key Value) int {
+	if m == nil {
+		return -1
+	}
+	if isScalarKey(key) {
+		if i, found := m.index[scalarKey{key.kind, key.scalar}]; found {
+			return i
+		}
+		return -1
+	}
+	for i, p := range m.pairs {
+		if p.Key.Equal(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isScalarKey(key Value) bool {
+	return key.kind != KindSequence && key.kind != KindMapping
+}
@@ -0,0 +1,130 @@
+package yamlot
+
+import (
+	"math"
+	"testing"
+)
+
+// config is the value of:
+//
+//	a:
+//	  - b: x
+//	    c: 1
+//	2: two
+//	[1, 2]: pair
+var config = NewMapping(
+	Pair{NewString("a"), NewSequence(
+		NewMapping(
+			Pair{NewString("b"), NewString("x")},
+			Pair{NewString("c"), NewInt(1)},
+		),
+	)},
+	Pair{NewInt(2), NewString("two")},
+	Pair{NewSequence(NewInt(1), NewInt(2)), NewString("pair")},
+)
+
+var valueGetTestTable = []struct {
+	name     string
+	path     []any
+	kind     Kind
+	expected string
+}{
+	{"string-keys-and-index", []any{"a", 0, "b"}, KindString, "x"},
+	{"int-value", []any{"a", 0, "c"}, KindInt, "1"},
+	{"int-key", []any{2}, KindString, "two"},
+	{"sequence-key", []any{NewSequence(NewInt(1), NewInt(2))}, KindString, "pair"},
+	{"missing-key", []any{"a", 0, "z"}, KindInvalid, ""},
+	{"index-out-of-range", []any{"a", 1, "b"}, KindInvalid, ""},
+	{"negative-index", []any{"a", -1}, KindInvalid, ""},
+	{"past-scalar", []any{"a", 0, "b", "c"}, KindInvalid, ""},
+	{"string-is-not-int-key", []any{"2"}, KindInvalid, ""},
+	{"unsupported-step", []any{1.5}, KindInvalid, ""},
+	{"empty-path", nil, KindMapping, ""},
+}
+
+// go test -count 1 -run '^TestValueGet$' ./...
+func TestValueGet(t *testing.T) {
+	for _, data := range valueGetTestTable {
+		v := config.Get(data.path...)
+		if v.Kind() != data.kind {
+			t.Errorf("%s: expecting kind %s, got: %s", data.name, data.kind, v.Kind())
+		}
+		if got := v.String(); got != data.expected {
+			t.Errorf("%s: expecting %q, got: %q", data.name, data.expected, got)
+		}
+	}
+}
+
+// go test -count 1 -run '^TestValueOrder$' ./...
+func TestValueOrder(t *testing.T) {
+	m := NewMapping().Mapping()
+	for _, k := range []string{"z", "a", "m"} {
+		m.Set(NewString(k), NewNull())
+	}
+	m.Set(NewString("a"), NewInt(1)) // keeps its position
+	m.Set(NewSequence(NewString("k")), NewInt(2))
+	m.Set(NewSequence(NewString("k")), NewInt(3)) // same sequence key
+
+	var keys string
+	for k := range m.All() {
+		keys += k.String() + ","
+	}
+	if keys != "z,a,m,," {
+		t.Errorf("expecting keys in insertion order, got: %q", keys)
+	}
+	if m.Len() != 4 {
+		t.Errorf("expecting 4 pairs, got: %d", m.Len())
+	}
+	if v, _ := m.Get(NewString("a")); v.Int() != 1 {
+		t.Errorf("expecting a=1, got: %v", v)
+	}
+	if v, _ := m.Get(NewSequence(NewString("k"))); v.Int() != 3 {
+		t.Errorf("expecting [k]=3, got: %v", v)
+	}
+	if _, found := m.Get(NewString("k")); found {
+		t.Error("unexpected key k")
+	}
+}
+
+// go test -count 1 -run '^TestValueScalars$' ./...
+func TestValueScalars(t *testing.T) {
+	table := []struct {
+		value    Value
+		expected string
+	}{
+		{NewNull(), "null"},
+		{NewBool(true), "true"},
+		{NewInt(-12), "-12"},
+		{NewFloat(1.5), "1.5"},
+		{NewFloat(math.Inf(-1)), "-.inf"},
+		{NewFloat(math.NaN()), ".nan"},
+		{NewString("text"), "text"},
+		{NewSequence(), ""},
+		{Value{}, ""},
+	}
+	for _, data := range table {
+		if got := data.value.String(); got != data.expected {
+			t.Errorf("%s: expecting %q, got: %q", data.value.Kind(), data.expected, got)
+		}
+	}
+	if NewInt(3).Float() != 3 || NewString("3").Int() != 0 || NewInt(1).Bool() {
+		t.Error("accessors must convert only int to float")
+	}
+}
+
+// go test -count 1 -run '^TestValueEqual$' ./...
+func TestValueEqual(t *testing.T) {
+	a := NewMapping(Pair{NewString("k"), NewSequence(NewInt(1))})
+	b := NewMapping(Pair{NewString("k"), NewSequence(NewInt(1))})
+	if !a.Equal(b) {
+		t.Error("expecting equal mappings")
+	}
+	if NewInt(1).Equal(NewFloat(1)) {
+		t.Error("int must differ from float")
+	}
+	c := NewMapping(Pair{NewString("x"), NewNull()}, Pair{NewString("y"), NewNull()})
+	d := NewMapping(Pair{NewString("y"), NewNull()}, Pair{NewString("x"), NewNull()})
+	if c.Equal(d) {
+		t.Error("mappings with different order must differ")
+	}
+}